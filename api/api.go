@@ -7,14 +7,17 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
 	"github.com/light-bull/lightbull/api/utils"
+	"github.com/light-bull/lightbull/dmx"
 	"github.com/light-bull/lightbull/events"
 	"github.com/light-bull/lightbull/frontend"
 	"github.com/light-bull/lightbull/hardware"
 	"github.com/light-bull/lightbull/persistence"
 	"github.com/light-bull/lightbull/shows"
+	"github.com/light-bull/lightbull/timing"
 )
 
 // API implements the REST API
@@ -23,16 +26,22 @@ type API struct {
 	shows       *shows.ShowCollection
 	eventhub    *events.EventHub
 	persistence *persistence.Persistence
+	dmx         *dmx.Input
+	timer       *timing.FrameTimer
+	streams     frameStreams
 	jwt         *utils.JWTManager
 }
 
 // New starts the listener for the REST API
-func New(hw *hardware.Hardware, shows *shows.ShowCollection, eventhub *events.EventHub, persistence *persistence.Persistence) (*API, error) {
+func New(hw *hardware.Hardware, shows *shows.ShowCollection, eventhub *events.EventHub, persistence *persistence.Persistence, dmx *dmx.Input, timer *timing.FrameTimer) (*API, error) {
 	api := API{
 		hw:          hw,
 		shows:       shows,
 		eventhub:    eventhub,
 		persistence: persistence,
+		dmx:         dmx,
+		timer:       timer,
+		streams:     frameStreams{stop: make(map[uuid.UUID]chan struct{})},
 	}
 
 	router := mux.NewRouter()
@@ -50,6 +59,8 @@ func New(hw *hardware.Hardware, shows *shows.ShowCollection, eventhub *events.Ev
 	api.initSystem(router)
 	api.initShows(router)
 	api.initSimulator(router)
+	api.initDMX(router)
+	api.initRecording(router)
 	api.initWS(router)
 
 	// Frontend
@@ -6,13 +6,17 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/light-bull/lightbull/api/utils"
+	"github.com/light-bull/lightbull/events"
 	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows"
 	"github.com/light-bull/lightbull/shows/effects"
 )
 
 func (api *API) initConfig(router *mux.Router) {
 	router.HandleFunc("/api/config", api.handleConfig)
 	router.HandleFunc("/api/config/parts", api.handleParts)
+	router.HandleFunc("/api/config/parts/reload", api.handlePartsReload)
+	router.HandleFunc("/api/config/parts/{name}", api.handlePartDetails)
 }
 
 func (api *API) handleConfig(w http.ResponseWriter, r *http.Request) {
@@ -22,16 +26,44 @@ func (api *API) handleConfig(w http.ResponseWriter, r *http.Request) {
 	utils.EnableCors(&w)
 
 	if r.Method == "GET" {
+		type colorCorrectionFormat struct {
+			Gamma        float64                          `json:"gamma"`
+			WhiteBalance map[string]hardware.WhiteBalance `json:"whiteBalance"`
+		}
+
+		type zoneFormat struct {
+			Name  string   `json:"name"`
+			Parts []string `json:"parts"`
+		}
+
 		type format struct {
-			Parts    []string          `json:"parts"`
-			Effects  map[string]string `json:"effects"`
-			Features []string          `json:"features"`
+			Parts           []string              `json:"parts"`
+			Zones           []zoneFormat          `json:"zones"`
+			Effects         map[string]string     `json:"effects"`
+			EffectTypes     []*effects.EffectInfo `json:"effectTypes"`
+			Features        []string              `json:"features"`
+			ColorCorrection colorCorrectionFormat `json:"colorCorrection"`
 		}
 
+		layout := api.hw.Led.Layout()
 		data := format{
-			Parts:    api.hw.Led.GetParts(),
-			Effects:  effects.GetEffects(),
-			Features: make([]string, 0),
+			Parts:       layout.GetParts(),
+			Zones:       make([]zoneFormat, 0, len(layout.GetZones())),
+			Effects:     effects.GetEffects(),
+			EffectTypes: effects.GetEffectInfos(),
+			Features:    make([]string, 0),
+			ColorCorrection: colorCorrectionFormat{
+				Gamma:        api.hw.Led.Gamma(),
+				WhiteBalance: make(map[string]hardware.WhiteBalance),
+			},
+		}
+
+		for _, zone := range layout.GetZones() {
+			data.Zones = append(data.Zones, zoneFormat{Name: zone, Parts: layout.GetZoneParts(zone)})
+		}
+
+		for _, part := range layout.GetParts() {
+			data.ColorCorrection.WhiteBalance[part] = layout.WhiteBalance(part)
 		}
 
 		if api.hw.System.EthernetConfig().Mode != hardware.EthUnmanaged {
@@ -52,28 +84,194 @@ func (api *API) handleParts(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method == "GET" {
 		type partFormat struct {
-			Name     string `json:"name"`
-			LedCount int    `json:"ledCount"`
+			Name       string              `json:"name"`
+			LedCount   int                 `json:"ledCount"`
+			Output     string              `json:"output,omitempty"`
+			Leds       [][]int             `json:"leds"`
+			ColorOrder string              `json:"colorOrder"`
+			Positions  []hardware.Position `json:"positions,omitempty"`
 		}
 
 		type format struct {
-			Parts []partFormat `json:"parts"`
+			Parts   []partFormat `json:"parts"`
+			NumLeds int          `json:"numLeds"`
 		}
 
-		partNames := api.hw.Led.GetParts()
+		numLeds := api.hw.NumLeds()
+
+		layout := api.hw.Led.Layout()
+		partNames := layout.GetParts()
 		parts := make([]partFormat, 0, len(partNames))
 
 		for _, partName := range partNames {
-			parts = append(parts, partFormat{
-				Name:     partName,
-				LedCount: api.hw.Led.GetNumLeds(partName),
-			})
+			part := partFormat{
+				Name:       partName,
+				LedCount:   layout.GetNumLeds(partName),
+				Output:     layout.GetOutput(partName),
+				Leds:       layout.GetRanges(partName),
+				ColorOrder: layout.ColorOrder(partName),
+			}
+
+			if layout.HasPositions(partName) {
+				part.Positions = make([]hardware.Position, part.LedCount)
+				for i := range part.Positions {
+					part.Positions[i] = layout.GetPosition(partName, i)
+				}
+			}
+
+			parts = append(parts, part)
 		}
 
-		data := format{Parts: parts}
+		data := format{Parts: parts, NumLeds: numLeds}
 
 		utils.WriteJSON(&w, data)
+	} else if r.Method == "POST" {
+		data := hardware.PartDefinition{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		api.hw.LockParts()
+		defer api.hw.UnlockParts()
+
+		parts := append(api.hw.PartDefinitions(), data)
+		if !api.setParts(&w, r, parts, nil) {
+			return
+		}
+
+		utils.WriteJSON(&w, data)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+func (api *API) handlePartsReload(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "POST" {
+		err := api.hw.ReloadParts(api.shows.CheckParts)
+		if err != nil {
+			utils.WriteError(&w, "Failed to reload parts: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.eventhub.PublishNew(events.PartsChanged, nil, nil, utils.GetConnectionID(r))
+		w.WriteHeader(http.StatusNoContent)
 	} else {
 		utils.WriteMethodNotAllowed(&w)
 	}
 }
+
+func (api *API) handlePartDetails(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	api.hw.LockParts()
+	defer api.hw.UnlockParts()
+
+	// find part
+	name := mux.Vars(r)["name"]
+
+	parts := api.hw.PartDefinitions()
+	index := -1
+	for i, part := range parts {
+		if part.Name == name {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		utils.WriteError(&w, "Unknown part", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == "GET" {
+		utils.WriteJSON(&w, parts[index])
+	} else if r.Method == "PUT" {
+		// get data from request
+		data := hardware.PartDefinition{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		if data.Name != "" {
+			parts[index].Name = data.Name
+		}
+		if data.Output != "" {
+			parts[index].Output = data.Output
+		}
+		if len(data.Leds) != 0 {
+			parts[index].Leds = data.Leds
+		}
+
+		// groups use the new name from the next frame on
+		var update func() []shows.GroupChange
+		if parts[index].Name != name {
+			newName := parts[index].Name
+			update = func() []shows.GroupChange { return api.shows.RenamePart(name, newName) }
+		}
+
+		if !api.setParts(&w, r, parts, update) {
+			return
+		}
+
+		utils.WriteJSON(&w, parts[index])
+	} else if r.Method == "DELETE" {
+		parts = append(parts[:index], parts[index+1:]...)
+
+		update := func() []shows.GroupChange { return api.shows.RemovePart(name) }
+		if !api.setParts(&w, r, parts, update) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+// setParts stores and applies the new part definitions, the parts lock of the hardware needs to be held. `update` is
+// called between two frames to change the groups that use renamed or deleted parts. If the parts are invalid or
+// cannot be stored, an error is written and false is returned.
+func (api *API) setParts(w *http.ResponseWriter, r *http.Request, parts []hardware.PartDefinition, update func() []shows.GroupChange) bool {
+	// the parts are stored before they are used, so that the running parts are always the stored ones
+	var saveErr error
+	save := func() error {
+		saveErr = api.persistence.SaveConfig("parts", parts, false)
+		return saveErr
+	}
+
+	var changes []shows.GroupChange
+	err := api.hw.SetPartDefinitions(parts, save, func(layout *hardware.PartLayout) {
+		if update != nil {
+			changes = update()
+		}
+	})
+	if saveErr != nil {
+		utils.WriteError(w, "Failed to save parts: "+saveErr.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if err != nil {
+		utils.WriteError(w, "Invalid parts: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	connectionID := utils.GetConnectionID(r)
+	api.eventhub.PublishNew(events.PartsChanged, nil, nil, connectionID)
+	for _, change := range changes {
+		if change.Deleted {
+			api.eventhub.PublishNew(events.GroupDeleted, change.Group, change.Show, connectionID)
+		} else {
+			api.eventhub.PublishNew(events.GroupChanged, change.Group, change.Show, connectionID)
+		}
+	}
+
+	return true
+}
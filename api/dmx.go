@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/light-bull/lightbull/api/utils"
+	"github.com/light-bull/lightbull/dmx"
+)
+
+func (api *API) initDMX(router *mux.Router) {
+	router.HandleFunc("/api/dmx/mappings", api.handleDMXMappings)
+	router.HandleFunc("/api/dmx/mappings/{id}", api.handleDMXMappingDetails)
+}
+
+func (api *API) handleDMXMappings(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		type format struct {
+			Mappings []dmx.Mapping `json:"mappings"`
+		}
+
+		utils.WriteJSON(&w, format{Mappings: api.dmx.Mappings()})
+	} else if r.Method == "POST" {
+		data := dmx.Mapping{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		mapping, err := api.dmx.NewMapping(data)
+		if errors.Is(err, dmx.ErrNotSaved) {
+			utils.WriteError(&w, "Failed to create mapping: "+err.Error(), http.StatusInternalServerError)
+			return
+		} else if err != nil {
+			utils.WriteError(&w, "Failed to create mapping: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		utils.WriteJSONWithStatus(&w, mapping, http.StatusCreated)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+func (api *API) handleDMXMappingDetails(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	// get mapping
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	mapping := api.dmx.FindMapping(id)
+	if mapping == nil {
+		utils.WriteError(&w, "Invalid or unknown ID", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == "GET" {
+		utils.WriteJSON(&w, mapping)
+	} else if r.Method == "PUT" {
+		data := dmx.Mapping{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		mapping, err = api.dmx.UpdateMapping(mapping, data)
+		if errors.Is(err, dmx.ErrUnknownMapping) {
+			utils.WriteError(&w, "Invalid or unknown ID", http.StatusNotFound)
+			return
+		} else if errors.Is(err, dmx.ErrNotSaved) {
+			utils.WriteError(&w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		utils.WriteJSON(&w, mapping)
+	} else if r.Method == "DELETE" {
+		err := api.dmx.DeleteMapping(mapping)
+		if errors.Is(err, dmx.ErrUnknownMapping) {
+			utils.WriteError(&w, "Invalid or unknown ID", http.StatusNotFound)
+			return
+		} else if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
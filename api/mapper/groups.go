@@ -12,6 +12,8 @@ type GroupJSON struct {
 	VisualId uuid.UUID  `json:"visualId"`
 	Parts    []string   `json:"parts"`
 	Effect   EffectJSON `json:"effect"`
+
+	Failure *shows.EffectFailure `json:"failure"`
 }
 
 type EffectJSON struct {
@@ -25,6 +27,7 @@ func MapGroup(visualId uuid.UUID, group *shows.Group) GroupJSON {
 		VisualId: visualId,
 		Parts:    make([]string, len(group.Parts())),
 		Effect:   MapEffect(&group.Effect),
+		Failure:  group.Failure(),
 	}
 
 	copy(data.Parts, group.Parts())
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/light-bull/lightbull/api/utils"
+)
+
+func (api *API) initRecording(router *mux.Router) {
+	router.HandleFunc("/api/recordings", api.handleRecordings)
+	router.HandleFunc("/api/recorder", api.handleRecorder)
+	router.HandleFunc("/api/player", api.handlePlayer)
+}
+
+func (api *API) handleRecordings(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		type format struct {
+			Recordings []string `json:"recordings"`
+		}
+
+		utils.WriteJSON(&w, format{Recordings: api.persistence.Recordings()})
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+func (api *API) handleRecorder(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		api.writeRecorderStatus(&w, http.StatusOK)
+	} else if r.Method == "POST" {
+		type inFormat struct {
+			Name string `json:"name"`
+		}
+		data := inFormat{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		file, err := api.persistence.RecordingPath(data.Name)
+		if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = api.hw.Recorder.Start(file, api.hw.Led)
+		if err != nil {
+			utils.WriteError(&w, "Failed to start recording: "+err.Error(), http.StatusConflict)
+			return
+		}
+
+		api.writeRecorderStatus(&w, http.StatusCreated)
+	} else if r.Method == "DELETE" {
+		err := api.hw.Recorder.Stop()
+		if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+func (api *API) handlePlayer(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		api.writePlayerStatus(&w, http.StatusOK)
+	} else if r.Method == "POST" {
+		type inFormat struct {
+			Name string `json:"name"`
+			Loop bool   `json:"loop"`
+		}
+		data := inFormat{}
+		err := utils.ParseJSON(&w, r, &data)
+		if err != nil {
+			return
+		}
+
+		file, err := api.persistence.RecordingPath(data.Name)
+		if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = api.hw.Player.Start(file, data.Loop)
+		if err != nil {
+			utils.WriteError(&w, "Failed to start playback: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		api.writePlayerStatus(&w, http.StatusCreated)
+	} else if r.Method == "DELETE" {
+		err := api.hw.Player.Stop()
+		if err != nil {
+			utils.WriteError(&w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+// writeRecorderStatus sends the state of the recorder
+func (api *API) writeRecorderStatus(w *http.ResponseWriter, status int) {
+	type format struct {
+		Recording bool   `json:"recording"`
+		Name      string `json:"name,omitempty"`
+	}
+
+	recording, file := api.hw.Recorder.Recording()
+	result := format{Recording: recording}
+	if recording {
+		result.Name = api.persistence.RecordingName(file)
+	}
+
+	utils.WriteJSONWithStatus(w, result, status)
+}
+
+// writePlayerStatus sends the state of the player
+func (api *API) writePlayerStatus(w *http.ResponseWriter, status int) {
+	type format struct {
+		Playing bool   `json:"playing"`
+		Name    string `json:"name,omitempty"`
+	}
+
+	playing, file := api.hw.Player.Playing()
+	result := format{Playing: playing}
+	if playing {
+		result.Name = api.persistence.RecordingName(file)
+	}
+
+	utils.WriteJSONWithStatus(w, result, status)
+}
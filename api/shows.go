@@ -223,8 +223,10 @@ func (api *API) handleGroups(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// add group
+		// add group, the parts must not be reloaded in between, since the reload checks the parts of all groups
+		api.hw.LockParts()
 		group, err := visual.NewGroup(data.Parts, data.EffectType)
+		api.hw.UnlockParts()
 		if err != nil {
 			utils.WriteError(&w, "Failed to create group: "+err.Error(), http.StatusBadRequest)
 			return
@@ -269,7 +271,9 @@ func (api *API) handleGroupDetails(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if len(data.Parts) != 0 {
+			api.hw.LockParts()
 			err = group.SetParts(data.Parts)
+			api.hw.UnlockParts()
 			if err != nil {
 				utils.WriteError(&w, err.Error(), http.StatusBadRequest)
 				return
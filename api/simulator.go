@@ -24,12 +24,14 @@ func (api *API) handleSimulator(w http.ResponseWriter, r *http.Request) {
 			B byte `json:"b"`
 		}
 
+		frame := api.hw.Led.GetFrame()
+
 		data := make(map[string][]colorformat)
-		for _, part := range api.hw.Led.GetParts() {
-			data[part] = make([]colorformat, api.hw.Led.GetNumLeds((part)))
+		for _, part := range frame.GetParts() {
+			data[part] = make([]colorformat, frame.GetNumLeds((part)))
 
-			for i := 0; i < api.hw.Led.GetNumLeds(part); i++ {
-				r, g, b := api.hw.Led.GetColor(part, i)
+			for i := 0; i < frame.GetNumLeds(part); i++ {
+				r, g, b := frame.GetColor(part, i)
 				data[part][i].R = r
 				data[part][i].G = g
 				data[part][i].B = b
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/light-bull/lightbull/api/utils"
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/spf13/viper"
+)
+
+// minStreamFPS is the lowest frame rate that can be requested for a frame stream
+const minStreamFPS = 1
+
+// frameStreams contains the running frame streams of the websocket clients
+type frameStreams struct {
+	stop map[uuid.UUID]chan struct{}
+	mux  sync.Mutex
+}
+
+// handleWSFrames starts or stops streaming the LED colors to the client. Every frame is sent as binary message with
+// RGB of all LEDs of all parts in the order of the layout that is sent before.
+func (api *API) handleWSFrames(ws *utils.WebsocketClient, payload *json.RawMessage) {
+	if !ws.Authenticated() {
+		ws.SendError("Unauthenticated")
+		return
+	}
+
+	// get frame rate, 0 stops the stream
+	type payloadFormat struct {
+		FPS float64 `json:"fps"`
+	}
+	data := payloadFormat{}
+	if payload == nil || json.Unmarshal(*payload, &data) != nil || data.FPS < 0 || math.IsNaN(data.FPS) || math.IsInf(data.FPS, 0) {
+		ws.SendError("Invalid data format")
+		return
+	}
+
+	if data.FPS == 0 {
+		api.stopFrameStream(ws)
+		return
+	}
+
+	if data.FPS < minStreamFPS {
+		ws.SendError("Frame rate too low")
+		return
+	}
+
+	// no need to send more frames than rendered
+	if maxFPS := viper.GetFloat64("leds.fps"); data.FPS > maxFPS {
+		data.FPS = maxFPS
+	}
+
+	interval := time.Duration(float64(time.Second) / data.FPS)
+	if interval <= 0 {
+		ws.SendError("Invalid frame rate")
+		return
+	}
+
+	// send frames
+	api.stopFrameStream(ws)
+	stop := make(chan struct{})
+
+	api.streams.mux.Lock()
+	api.streams.stop[ws.ID()] = stop
+	api.streams.mux.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		layout := api.hw.Led.Layout()
+		api.sendFrameLayout(ws, layout, data.FPS, stop)
+
+		for {
+			select {
+			case <-ticker.C:
+				frame := api.hw.Led.GetFrame()
+
+				// the parts were changed: send the new layout and continue with the next frame
+				if frame.PartLayout != layout {
+					layout = frame.PartLayout
+					api.sendFrameLayout(ws, layout, data.FPS, stop)
+					continue
+				}
+
+				buffer := make([]byte, 0, 3*frame.GetNumLedsMultiPart(frame.GetParts()))
+				for _, part := range frame.GetParts() {
+					for i := 0; i < frame.GetNumLeds(part); i++ {
+						r, g, b := frame.GetColor(part, i)
+						buffer = append(buffer, r, g, b)
+					}
+				}
+
+				ws.SendBinary(buffer)
+			case <-ws.Done():
+				api.stopFrameStream(ws)
+				return
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// sendFrameLayout sends the names and number of LEDs of the parts in the order of the binary frames. It gives up if the
+// connection is closed or the stream is stopped while waiting for the client, the stream loop ends the stream then.
+func (api *API) sendFrameLayout(ws *utils.WebsocketClient, layout *hardware.PartLayout, fps float64, stop <-chan struct{}) {
+	type partFormat struct {
+		Name    string `json:"name"`
+		NumLeds int    `json:"numLeds"`
+	}
+	type layoutFormat struct {
+		FPS   float64      `json:"fps"`
+		Parts []partFormat `json:"parts"`
+	}
+
+	data := layoutFormat{FPS: fps, Parts: []partFormat{}}
+	for _, part := range layout.GetParts() {
+		data.Parts = append(data.Parts, partFormat{Name: part, NumLeds: layout.GetNumLeds(part)})
+	}
+
+	ws.SendMessageCancelable("frames_layout", data, stop)
+}
+
+// stopFrameStream stops the frame stream of the client if it is running
+func (api *API) stopFrameStream(ws *utils.WebsocketClient) {
+	api.streams.mux.Lock()
+	defer api.streams.mux.Unlock()
+
+	if stop, exists := api.streams.stop[ws.ID()]; exists {
+		close(stop)
+		delete(api.streams.stop, ws.ID())
+	}
+}
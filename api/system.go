@@ -11,6 +11,8 @@ import (
 func (api *API) initSystem(router *mux.Router) {
 	router.HandleFunc("/api/shutdown", api.handleShutdown)
 	router.HandleFunc("/api/ethernet", api.handleEthernet)
+	router.HandleFunc("/api/power", api.handlePower)
+	router.HandleFunc("/api/timing", api.handleTiming)
 }
 
 func (api *API) handleShutdown(w http.ResponseWriter, r *http.Request) {
@@ -52,3 +54,29 @@ func (api *API) handleEthernet(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 	}
 }
+
+func (api *API) handlePower(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		utils.WriteJSON(&w, api.hw.Led.PowerEstimate())
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
+
+func (api *API) handleTiming(w http.ResponseWriter, r *http.Request) {
+	if !api.authenticate(&w, r) {
+		return
+	}
+	utils.EnableCors(&w)
+
+	if r.Method == "GET" {
+		utils.WriteJSON(&w, api.timer.Stats())
+	} else {
+		utils.WriteMethodNotAllowed(&w)
+	}
+}
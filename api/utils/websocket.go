@@ -37,8 +37,10 @@ type WebsocketClient struct {
 	eventhub *events.EventHub
 	conn     *websocket.Conn
 
-	events chan *events.Event
-	send   chan []byte
+	events     chan *events.Event
+	send       chan []byte
+	sendBinary chan []byte
+	done       chan struct{}
 
 	handlers map[string]WebsocketHandler
 
@@ -52,8 +54,10 @@ func NewWebsocketClient(conn *websocket.Conn, eventhub *events.EventHub) *Websoc
 		eventhub: eventhub,
 		conn:     conn,
 
-		events: make(chan *events.Event, 256),
-		send:   make(chan []byte, 256),
+		events:     make(chan *events.Event, 256),
+		send:       make(chan []byte, 256),
+		sendBinary: make(chan []byte, 4),
+		done:       make(chan struct{}),
 
 		handlers: make(map[string]WebsocketHandler),
 
@@ -103,6 +107,38 @@ func (client *WebsocketClient) SendMessage(topic string, payload interface{}) {
 	client.send <- data
 }
 
+// SendMessageCancelable sends a message over the websocket connection like SendMessage, but gives up if the connection
+// or `cancel` is closed while waiting for the client. It returns false if the message was not sent.
+func (client *WebsocketClient) SendMessageCancelable(topic string, payload interface{}, cancel <-chan struct{}) bool {
+	data, err := json.Marshal(events.NewEvent(topic, payload, nil, uuid.Nil))
+	if err != nil {
+		log.Println("Failed to serialize event for websocket")
+		return false
+	}
+
+	select {
+	case client.send <- data:
+		return true
+	case <-client.done:
+		return false
+	case <-cancel:
+		return false
+	}
+}
+
+// SendBinary sends binary data over the websocket connection. If the client is too slow, the data is dropped.
+func (client *WebsocketClient) SendBinary(data []byte) {
+	select {
+	case client.sendBinary <- data:
+	default:
+	}
+}
+
+// Done returns a channel that is closed when the connection was closed
+func (client *WebsocketClient) Done() <-chan struct{} {
+	return client.done
+}
+
 // SendError sends an error back over the websocket connection
 func (client *WebsocketClient) SendError(msg string) {
 	type errorFormat struct {
@@ -146,6 +182,7 @@ func (client *WebsocketClient) readPump() {
 	defer func() {
 		client.eventhub.UnregisterClient(client)
 		client.conn.Close()
+		close(client.done)
 	}()
 
 	// set max message size
@@ -235,6 +272,13 @@ func (client *WebsocketClient) writePump() {
 			if err := w.Close(); err != nil {
 				return
 			}
+		case data := <-client.sendBinary:
+			client.updateWriteDeadline()
+			if err := client.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		case <-client.done:
+			return
 		case <-ticker.C:
 			// it's time for a ping
 			client.updateWriteDeadline()
@@ -36,6 +36,7 @@ func (api *API) handleWebsocketClient(w http.ResponseWriter, r *http.Request) {
 	client := utils.NewWebsocketClient(conn, api.eventhub)
 	client.AddHandler("identify", api.handleWSIdentify)
 	client.AddHandler("parameter", api.handleWSParameter)
+	client.AddHandler("frames", api.handleWSFrames)
 }
 
 func (api *API) handleWSIdentify(ws *utils.WebsocketClient, payload *json.RawMessage) {
@@ -29,8 +29,11 @@ var calibrateCmd = &cobra.Command{
 		readConfigFile()
 
 		// Init LEDs
+		layout := hardware.NewPartLayout()
+		layout.AddPart("calibrate", 0, numberLeds)
+
 		led := hardware.NewLED()
-		led.AddPart("calibrate", 0, numberLeds)
+		led.SetPartLayout(layout)
 		if err := led.Init(); err != nil {
 			log.Fatal(err)
 		}
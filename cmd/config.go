@@ -23,11 +23,17 @@ func readConfigFile() {
 	viper.SetDefault("directories.tmp", "/var/cache/lightbull")
 
 	viper.SetDefault("leds.parts", nil)
-	viper.SetDefault("leds.brightnessCap", 80)
+	viper.SetDefault("leds.driver", "apa102")
+	viper.SetDefault("leds.count", 0)
+	viper.SetDefault("leds.power.milliampsPerChannel", 20)
+	viper.SetDefault("leds.power.idleMilliampsPerLed", 1)
 	viper.SetDefault("leds.spiMHz", 1)
 	viper.SetDefault("leds.fps", 25)
 	viper.SetDefault("leds.drawDummy", false)
 
+	viper.SetDefault("dmxInput.artnet", false)
+	viper.SetDefault("dmxInput.sacn", false)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		log.Fatal(fmt.Errorf("Fatal error config file: %s", err))
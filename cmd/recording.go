@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var recordingHost string
+var recordingPassword string
+var recordingLoop bool
+
+func init() {
+	rootCmd.AddCommand(recordingCmd)
+	recordingCmd.AddCommand(recordingStartCmd)
+	recordingCmd.AddCommand(recordingStopCmd)
+	recordingCmd.AddCommand(recordingPlayCmd)
+	recordingCmd.AddCommand(recordingHaltCmd)
+	recordingCmd.AddCommand(recordingListCmd)
+
+	recordingCmd.PersistentFlags().StringVarP(&recordingHost, "host", "H", "localhost", "Host on which lightbull is running")
+	recordingCmd.PersistentFlags().StringVarP(&recordingPassword, "password", "p", "", "Password for the API (asked for if not set)")
+	recordingPlayCmd.Flags().BoolVarP(&recordingLoop, "loop", "l", false, "Repeat the recording")
+}
+
+var recordingCmd = &cobra.Command{
+	Use:   "recording",
+	Short: "Record and play back frames",
+	Long: `Control the recording and playback of frames of a running lightbull instance.
+	The commands use the REST API, so the port is taken from the config file.`,
+}
+
+var recordingStartCmd = &cobra.Command{
+	Use:   "start NAME",
+	Short: "Start recording of frames",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data := map[string]interface{}{"name": args[0]}
+		if err := recordingRequest("POST", "/api/recorder", data, nil); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Recording " + args[0])
+	},
+}
+
+var recordingStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop recording of frames",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := recordingRequest("DELETE", "/api/recorder", nil, nil); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Recording stopped")
+	},
+}
+
+var recordingPlayCmd = &cobra.Command{
+	Use:   "play NAME",
+	Short: "Play back a recording",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data := map[string]interface{}{"name": args[0], "loop": recordingLoop}
+		if err := recordingRequest("POST", "/api/player", data, nil); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Playing " + args[0])
+	},
+}
+
+var recordingHaltCmd = &cobra.Command{
+	Use:   "halt",
+	Short: "Stop playback of a recording",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := recordingRequest("DELETE", "/api/player", nil, nil); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Playback stopped")
+	},
+}
+
+var recordingListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all recordings",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		result := struct {
+			Recordings []string `json:"recordings"`
+		}{}
+		if err := recordingRequest("GET", "/api/recordings", nil, &result); err != nil {
+			log.Fatal(err)
+		}
+
+		for _, name := range result.Recordings {
+			fmt.Println(name)
+		}
+	},
+}
+
+// recordingRequest authenticates against the REST API and sends a request. The response is stored in `result` if it
+// is not nil.
+func recordingRequest(method string, path string, data interface{}, result interface{}) error {
+	readConfigFile()
+	baseURL := fmt.Sprintf("http://%s:%d", recordingHost, viper.GetInt("api.listen"))
+
+	// get password
+	password := recordingPassword
+	if password == "" {
+		fmt.Print("Password: ")
+		input, err := terminal.ReadPassword(0)
+		fmt.Println()
+		if err != nil {
+			return err
+		}
+		password = string(input)
+	}
+
+	// get JWT
+	auth := struct {
+		Jwt string `json:"jwt"`
+	}{}
+	if err := apiRequest("POST", baseURL+"/api/auth", "", map[string]string{"password": password}, &auth); err != nil {
+		return err
+	}
+
+	return apiRequest(method, baseURL+path, auth.Jwt, data, result)
+}
+
+// apiRequest sends a request with optional JSON body to the REST API and parses the JSON response
+func apiRequest(method string, url string, jwt string, data interface{}, result interface{}) error {
+	var body bytes.Buffer
+	if data != nil {
+		if err := json.NewEncoder(&body).Encode(data); err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest(method, url, &body)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if jwt != "" {
+		request.Header.Set("Authorization", "Bearer "+jwt)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		apiError := struct {
+			Error string `json:"error"`
+		}{}
+		json.NewDecoder(response.Body).Decode(&apiError)
+		if apiError.Error == "" {
+			apiError.Error = response.Status
+		}
+		return errors.New(apiError.Error)
+	}
+
+	if result != nil {
+		return json.NewDecoder(response.Body).Decode(result)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows"
+	"github.com/light-bull/lightbull/shows/effects"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var renderVisual string
+var renderOutput string
+var renderDuration float64
+var renderFPS float64
+var renderScale int
+var renderSeed int64
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringVarP(&renderVisual, "visual", "v", "", "Name or ID of the visual (default: first visual of the show)")
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", "visual.gif", "Output file, .gif for an animation or .png for a waterfall image")
+	renderCmd.Flags().Float64VarP(&renderDuration, "duration", "d", 5, "Rendered time in seconds")
+	renderCmd.Flags().Float64VarP(&renderFPS, "fps", "f", 0, "Frames per second (default: leds.fps from config file)")
+	renderCmd.Flags().IntVarP(&renderScale, "scale", "s", 8, "Size of a LED in pixels")
+	renderCmd.Flags().Int64Var(&renderSeed, "seed", 0, "Seed for effects with random numbers (default: random output)")
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render SHOW_FILE",
+	Short: "Render a visual into an image",
+	Long: `Renders a visual of a show without connected LEDs. The show is read from a JSON file as it is stored in the
+	configuration directory and the parts are taken from the config file.
+	A GIF file contains an animation with one row per part, a PNG file contains one row per frame with all LEDs.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		readConfigFile()
+
+		// load show and visual
+		show, err := loadShowFile(args[0])
+		if err != nil {
+			log.Fatal("Cannot load show: " + err.Error())
+		}
+
+		visual := findVisual(show, renderVisual)
+		if visual == nil {
+			log.Fatal("Visual not found")
+		}
+
+		// check options
+		fps := renderFPS
+		if fps <= 0 {
+			fps = viper.GetFloat64("leds.fps")
+		}
+		if fps <= 0 || renderDuration <= 0 || renderScale <= 0 {
+			log.Fatal("FPS, duration and scale need to be greater than zero")
+		}
+
+		// same output for every run
+		if cmd.Flags().Changed("seed") {
+			seedEffects(visual, renderSeed)
+		}
+
+		// LEDs in memory
+		hw, err := hardware.NewOffline()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// render
+		numFrames := int(renderDuration * fps)
+		nanoseconds := int64(1000000000 / fps)
+
+		switch strings.ToLower(filepath.Ext(renderOutput)) {
+		case ".gif":
+			err = renderGIF(hw, visual, numFrames, nanoseconds, fps)
+		case ".png":
+			err = renderPNG(hw, visual, numFrames, nanoseconds)
+		default:
+			err = errors.New("Unknown file type, use .gif or .png")
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Rendered %d frames of visual \"%s\" to %s\n", numFrames, visual.Name, renderOutput)
+	},
+}
+
+// loadShowFile reads a show from a JSON file
+func loadShowFile(file string) (*shows.Show, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	show := shows.Show{}
+	err = json.Unmarshal(data, &show)
+	if err != nil {
+		return nil, err
+	}
+
+	return &show, nil
+}
+
+// findVisual returns the visual with the given name or ID or the first visual if `visual` is empty
+func findVisual(show *shows.Show, visual string) *shows.Visual {
+	for _, candidate := range show.Visuals() {
+		if visual == "" || candidate.ID.String() == visual || candidate.Name == visual {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// seedEffects seeds the effects of the visual that use random numbers. Every group gets a different seed, so that
+// groups with the same effect do not look the same.
+func seedEffects(visual *shows.Visual, seed int64) {
+	for i, group := range visual.Groups() {
+		if effect, ok := group.Effect.(effects.RandomEffect); ok {
+			effect.Seed(seed + int64(i))
+		}
+	}
+}
+
+// updateVisual renders the next frame of the visual. A failed effect is disabled and would leave its parts dark in the
+// rest of the output, so the rendering is stopped with an error instead.
+func updateVisual(hw *hardware.Hardware, visual *shows.Visual, nanoseconds int64) error {
+	if failures := visual.Update(hw, nanoseconds); len(failures) > 0 {
+		return fmt.Errorf("Effect %s of group %s failed: %s", failures[0].Effect, failures[0].GroupID, failures[0].Error)
+	}
+
+	return nil
+}
+
+// renderGIF writes an animation with one row per part
+func renderGIF(hw *hardware.Hardware, visual *shows.Visual, numFrames int, nanoseconds int64, fps float64) error {
+	parts := hw.Led.GetParts()
+
+	width := 0
+	for _, part := range parts {
+		if hw.Led.GetNumLeds(part) > width {
+			width = hw.Led.GetNumLeds(part)
+		}
+	}
+	bounds := image.Rect(0, 0, width*renderScale, len(parts)*renderScale)
+
+	// delay is in 100th of a second
+	delay := int(100/fps + 0.5)
+	if delay < 2 {
+		delay = 2
+	}
+
+	animation := gif.GIF{}
+	frame := image.NewNRGBA(bounds)
+
+	for i := 0; i < numFrames; i++ {
+		if err := updateVisual(hw, visual, nanoseconds); err != nil {
+			return err
+		}
+
+		draw.Draw(frame, bounds, image.Black, image.Point{}, draw.Src)
+		for row, part := range parts {
+			for led := 0; led < hw.Led.GetNumLeds(part); led++ {
+				r, g, b := hw.Led.GetColor(part, led)
+				rect := image.Rect(led*renderScale, row*renderScale, (led+1)*renderScale, (row+1)*renderScale)
+				draw.Draw(frame, rect, image.NewUniform(color.NRGBA{R: r, G: g, B: b, A: 255}), image.Point{}, draw.Src)
+			}
+		}
+
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, bounds, frame, image.Point{})
+
+		animation.Image = append(animation.Image, paletted)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	file, err := os.Create(renderOutput)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gif.EncodeAll(file, &animation)
+}
+
+// renderPNG writes a "waterfall" image with one row per frame and all LEDs of all parts next to each other
+func renderPNG(hw *hardware.Hardware, visual *shows.Visual, numFrames int, nanoseconds int64) error {
+	parts := hw.Led.GetParts()
+
+	width := 0
+	for _, part := range parts {
+		width += hw.Led.GetNumLeds(part)
+	}
+
+	waterfall := image.NewNRGBA(image.Rect(0, 0, width*renderScale, numFrames*renderScale))
+
+	for i := 0; i < numFrames; i++ {
+		if err := updateVisual(hw, visual, nanoseconds); err != nil {
+			return err
+		}
+
+		x := 0
+		for _, part := range parts {
+			for led := 0; led < hw.Led.GetNumLeds(part); led++ {
+				r, g, b := hw.Led.GetColor(part, led)
+				rect := image.Rect(x*renderScale, i*renderScale, (x+1)*renderScale, (i+1)*renderScale)
+				draw.Draw(waterfall, rect, image.NewUniform(color.NRGBA{R: r, G: g, B: b, A: 255}), image.Point{}, draw.Src)
+				x++
+			}
+		}
+	}
+
+	file, err := os.Create(renderOutput)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, waterfall)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/lightbull"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var simulateLogFile string
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Run the control server and show the LEDs in the terminal",
+	Long: `Runs the complete controller like "run", but the LEDs are not connected. Instead, every part is shown as a row
+	of colored blocks in the terminal (which needs to support truecolor). If a part has more LEDs than the terminal has
+	columns, neighboring LEDs are combined. Log messages are written to a file while the LEDs are shown.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readConfigFile()
+
+		lb, err := lightbull.NewSimulated()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// log messages would end up between the rows of the LEDs
+		if terminal.IsTerminal(int(os.Stderr.Fd())) {
+			logFile, err := os.OpenFile(simulateLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer logFile.Close()
+
+			log.SetOutput(logFile)
+			defer log.SetOutput(os.Stderr)
+		}
+
+		// hide cursor and clear screen
+		fmt.Print("\033[?25l\033[2J")
+
+		stop := make(chan bool)
+		done := make(chan bool)
+		go func() {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / viper.GetFloat64("leds.fps")))
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					drawTerminal(lb.Hardware.Led)
+				case <-stop:
+					done <- true
+					return
+				}
+			}
+		}()
+
+		waitQuit()
+		stop <- true
+		<-done
+
+		// reset colors and show cursor again
+		fmt.Print("\033[0m\033[?25h")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().StringVarP(&simulateLogFile, "log", "l", filepath.Join(os.TempDir(), "lightbull-simulate.log"), "File for log messages while the LEDs are shown")
+}
+
+// drawTerminal prints one row with the name and the colors of the LEDs per part at the top of the terminal. The rows
+// are not wider than the terminal, otherwise they would wrap and the next frame would not be drawn over this one.
+func drawTerminal(led *hardware.LED) {
+	frame := led.GetFrame()
+
+	width := 0
+	for _, part := range frame.GetParts() {
+		if len(part) > width {
+			width = len(part)
+		}
+	}
+
+	// the last column is left empty, some terminals already wrap when it is written
+	columns := terminalWidth() - width - 2
+	if columns < 1 {
+		columns = 1
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\033[H")
+
+	for _, part := range frame.GetParts() {
+		out.WriteString(part + strings.Repeat(" ", width-len(part)+1))
+
+		// if there are more LEDs than columns, a block shows the average color of neighboring LEDs
+		numLeds := frame.GetNumLeds(part)
+		numBlocks := numLeds
+		if numBlocks > columns {
+			numBlocks = columns
+		}
+
+		for block := 0; block < numBlocks; block++ {
+			first := block * numLeds / numBlocks
+			last := (block + 1) * numLeds / numBlocks
+
+			var sumR, sumG, sumB int
+			for i := first; i < last; i++ {
+				r, g, b := frame.GetColor(part, i)
+				sumR += int(r)
+				sumG += int(g)
+				sumB += int(b)
+			}
+
+			count := last - first
+			fmt.Fprintf(out, "\033[38;2;%d;%d;%dm█", sumR/count, sumG/count, sumB/count)
+		}
+
+		out.WriteString("\033[0m\033[K\n")
+	}
+
+	out.Flush()
+}
+
+// terminalWidth returns the number of columns of the terminal or 80 if it is unknown
+func terminalWidth() int {
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return 80
+	}
+	return width
+}
@@ -0,0 +1,268 @@
+package dmx
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/light-bull/lightbull/events"
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/persistence"
+	"github.com/light-bull/lightbull/shows"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// configName is the name of the persisted mapping configuration
+const configName = "dmx"
+
+// ErrUnknownMapping is returned if a mapping is changed or deleted that does not exist (anymore)
+var ErrUnknownMapping = errors.New("Unknown mapping")
+
+// ErrNotSaved is returned if the changed mappings cannot be stored on disk. The change is not applied then.
+var ErrNotSaved = errors.New("Cannot save DMX mappings")
+
+// Input maps DMX data from lighting desks onto parameters
+type Input struct {
+	hw          *hardware.Hardware
+	shows       *shows.ShowCollection
+	eventhub    *events.EventHub
+	persistence *persistence.Persistence
+
+	mappings []*Mapping
+
+	mux sync.Mutex
+}
+
+// NewInput loads the stored mappings and starts handling the received DMX data
+func NewInput(hw *hardware.Hardware, shows *shows.ShowCollection, eventhub *events.EventHub, persistence *persistence.Persistence) *Input {
+	input := Input{
+		hw:          hw,
+		shows:       shows,
+		eventhub:    eventhub,
+		persistence: persistence,
+		mappings:    make([]*Mapping, 0),
+	}
+
+	if persistence.HasConfig(configName) {
+		err := persistence.LoadConfig(configName, &input.mappings)
+		if err != nil {
+			log.Print("Error while loading DMX mappings: " + err.Error())
+		}
+	}
+
+	for _, mapping := range input.mappings {
+		input.joinUniverse(*mapping)
+	}
+
+	hw.DMXInput.SetHandler(input.handleDMX)
+
+	return &input
+}
+
+// Mappings returns a copy of all mappings, so that they can be read while they are changed
+func (input *Input) Mappings() []Mapping {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	mappings := make([]Mapping, len(input.mappings))
+	for i, mapping := range input.mappings {
+		mappings[i] = *mapping
+	}
+	return mappings
+}
+
+// FindMapping returns a copy of the mapping with the given ID or nil for malformed and non-existing IDs
+func (input *Input) FindMapping(idStr string) *Mapping {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil
+	}
+
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	for _, mapping := range input.mappings {
+		if mapping.ID == id {
+			found := *mapping
+			return &found
+		}
+	}
+
+	return nil
+}
+
+// NewMapping validates and adds a new mapping, a copy of it is returned. If the mappings cannot be saved, the mapping is
+// not added and an error wrapping ErrNotSaved is returned.
+func (input *Input) NewMapping(mapping Mapping) (*Mapping, error) {
+	mapping.ID = uuid.New() // FIXME: uuid is randomly generated, so there could be a collission
+
+	if err := input.validate(&mapping); err != nil {
+		return nil, err
+	}
+
+	added := mapping
+	input.mux.Lock()
+	input.mappings = append(input.mappings, &added)
+	if err := input.save(); err != nil {
+		input.mappings = input.mappings[:len(input.mappings)-1]
+		input.mux.Unlock()
+		return nil, err
+	}
+	input.mux.Unlock()
+
+	input.joinUniverse(mapping)
+
+	return &mapping, nil
+}
+
+// UpdateMapping validates the new values and changes the existing mapping with the ID of `mapping`. A copy of the
+// changed mapping is returned. If the mappings cannot be saved, the mapping is not changed and an error wrapping
+// ErrNotSaved is returned.
+func (input *Input) UpdateMapping(mapping *Mapping, values Mapping) (*Mapping, error) {
+	values.ID = mapping.ID
+
+	if err := input.validate(&values); err != nil {
+		return nil, err
+	}
+
+	input.mux.Lock()
+	var changed *Mapping
+	for _, cur := range input.mappings {
+		if cur.ID == values.ID {
+			changed = cur
+			break
+		}
+	}
+
+	if changed == nil {
+		input.mux.Unlock()
+		return nil, ErrUnknownMapping
+	}
+
+	old := *changed
+	*changed = values
+	if err := input.save(); err != nil {
+		*changed = old
+		input.mux.Unlock()
+		return nil, err
+	}
+	input.mux.Unlock()
+
+	input.joinUniverse(values)
+
+	return &values, nil
+}
+
+// DeleteMapping deletes the mapping or returns ErrUnknownMapping if it does not exist. If the mappings cannot be saved,
+// the mapping is kept and an error wrapping ErrNotSaved is returned.
+func (input *Input) DeleteMapping(mapping *Mapping) error {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	for pos, cur := range input.mappings {
+		if mapping.ID != cur.ID {
+			continue
+		}
+
+		mappings := input.mappings
+		input.mappings = append(append(make([]*Mapping, 0, len(mappings)-1), mappings[:pos]...), mappings[pos+1:]...)
+		if err := input.save(); err != nil {
+			input.mappings = mappings
+			return err
+		}
+		return nil
+	}
+
+	return ErrUnknownMapping
+}
+
+// validate checks the mapping and that the parameter exists
+func (input *Input) validate(mapping *Mapping) error {
+	if err := mapping.validate(); err != nil {
+		return err
+	}
+
+	_, _, _, parameter := input.shows.FindParameter(mapping.Parameter.String())
+	if parameter == nil {
+		return errors.New("Invalid or unknown parameter ID")
+	}
+
+	if mapping.Channel+numChannels(parameter.Type())-1 > 512 {
+		return errors.New("Channels out of range")
+	}
+
+	if _, err := convert(parameter.Type(), make([]byte, numChannels(parameter.Type()))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// save stores the mappings on disk. The lock needs to be held.
+func (input *Input) save() error {
+	if err := input.persistence.SaveConfig(configName, input.mappings, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotSaved, err)
+	}
+	return nil
+}
+
+// joinUniverse makes sure that multicast data for the universe of the mapping is received
+func (input *Input) joinUniverse(mapping Mapping) {
+	if mapping.Protocol != hardware.ProtocolSACN {
+		return
+	}
+
+	if err := input.hw.DMXInput.JoinUniverse(mapping.Universe); err != nil {
+		log.Print("Failed to join sACN universe: " + err.Error())
+	}
+}
+
+// handleDMX is called for received DMX data and updates the mapped parameters. The parameters are read by the effects
+// while a frame is drawn, so they are only changed while the hardware is locked.
+func (input *Input) handleDMX(protocol string, universe int, data []byte) {
+	var changed []*parameters.Parameter
+
+	input.hw.Lock()
+	for _, mapping := range input.Mappings() {
+		if mapping.Protocol != protocol || mapping.Universe != universe {
+			continue
+		}
+
+		_, _, _, parameter := input.shows.FindParameter(mapping.Parameter.String())
+		if parameter == nil {
+			continue
+		}
+
+		// check that all channels were sent
+		first := mapping.Channel - 1
+		last := first + numChannels(parameter.Type())
+		if last > len(data) {
+			continue
+		}
+
+		value, err := convert(parameter.Type(), data[first:last])
+		if err != nil {
+			continue
+		}
+
+		// DMX is sent continuously, so only handle changes
+		if parameter.Get() == value {
+			continue
+		}
+
+		err = parameter.Set(value)
+		if err != nil {
+			continue
+		}
+
+		changed = append(changed, parameter)
+	}
+	input.hw.Unlock()
+
+	// trigger events, the event hub may block on slow clients, so not while the hardware is locked
+	for i := range changed {
+		input.eventhub.PublishNew(events.ParameterChanged, &changed[i], nil, uuid.Nil)
+	}
+}
@@ -0,0 +1,225 @@
+package dmx
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/light-bull/lightbull/events"
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/persistence"
+	"github.com/light-bull/lightbull/shows"
+	"github.com/light-bull/lightbull/shows/effects"
+	"github.com/light-bull/lightbull/shows/parameters"
+	"github.com/spf13/viper"
+)
+
+// testClient collects the events of the event hub
+type testClient struct {
+	events chan *events.Event
+}
+
+func (client *testClient) EventChan() chan *events.Event {
+	return client.events
+}
+
+// newTestInput creates a DMX input without network connections and a show with a comet effect. The parameters of the
+// effect are returned by their key.
+func newTestInput(t *testing.T) (*Input, map[string]*parameters.Parameter) {
+	viper.Set("directories.config", t.TempDir())
+	t.Cleanup(viper.Reset)
+
+	eventhub := events.NewEventHub()
+	store, err := persistence.NewPersistence(eventhub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	showCollection := shows.NewShowCollection()
+	show, err := showCollection.NewShow("show", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := show.NewVisual("visual").NewGroup([]string{"part"}, effects.Comet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := make(map[string]*parameters.Parameter)
+	for _, parameter := range group.Effect.Parameters() {
+		params[parameter.Key] = parameter
+	}
+
+	input := &Input{
+		hw:          &hardware.Hardware{},
+		shows:       showCollection,
+		eventhub:    eventhub,
+		persistence: store,
+		mappings:    make([]*Mapping, 0),
+	}
+	return input, params
+}
+
+// changedParameters returns the parameters of all ParameterChanged events that were published so far
+func changedParameters(t *testing.T, eventhub *events.EventHub, client *testClient) []*parameters.Parameter {
+	// the events are distributed in order, so all events are received when the marker arrives
+	const marker = "test_marker"
+	eventhub.PublishNew(marker, nil, nil, uuid.Nil)
+
+	var changed []*parameters.Parameter
+	for event := range client.events {
+		switch event.Topic {
+		case marker:
+			return changed
+		case events.ParameterChanged:
+			changed = append(changed, *event.Payload.(**parameters.Parameter))
+		default:
+			t.Errorf("unexpected event %s", event.Topic)
+		}
+	}
+	return changed
+}
+
+func TestInputValidate(t *testing.T) {
+	input, params := newTestInput(t)
+
+	tests := []struct {
+		name    string
+		mapping Mapping
+		valid   bool
+	}{
+		{"color", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 1, Parameter: params["color"].ID}, true},
+		// colors need three channels
+		{"color at the end", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 510, Parameter: params["color"].ID}, true},
+		{"color out of range", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 511, Parameter: params["color"].ID}, false},
+		{"percent at the end", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 512, Parameter: params["speed"].ID}, true},
+		{"integer", Mapping{Protocol: hardware.ProtocolSACN, Universe: 1, Channel: 1, Parameter: params["count"].ID}, true},
+		{"boolean", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 1, Parameter: params["reversed"].ID}, true},
+		{"unknown parameter", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 1, Parameter: uuid.New()}, false},
+		{"no parameter", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 1}, false},
+		{"invalid mapping", Mapping{Protocol: hardware.ProtocolArtNet, Channel: 0, Parameter: params["speed"].ID}, false},
+	}
+
+	for _, test := range tests {
+		err := input.validate(&test.mapping)
+		if test.valid && err != nil {
+			t.Errorf("%s: valid mapping was rejected: %s", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: invalid mapping was accepted", test.name)
+		}
+	}
+}
+
+func TestHandleDMX(t *testing.T) {
+	input, params := newTestInput(t)
+
+	client := &testClient{events: make(chan *events.Event, 10)}
+	input.eventhub.RegisterClient(client)
+
+	params["color"].Set(color.NRGBA{A: 255})
+	params["speed"].Set(0)
+	params["count"].Set(0)
+	params["randomDecay"].Set(false)
+	params["reversed"].Set(false)
+
+	input.mappings = []*Mapping{
+		{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 1, Parameter: params["color"].ID},
+		{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 4, Parameter: params["speed"].ID},
+		{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 5, Parameter: params["count"].ID},
+		{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 6, Parameter: params["randomDecay"].ID},
+		{Protocol: hardware.ProtocolSACN, Universe: 1, Channel: 1, Parameter: params["reversed"].ID},
+	}
+
+	tests := []struct {
+		name     string
+		protocol string
+		universe int
+		data     []byte
+		changed  []string
+	}{
+		{"all changed", hardware.ProtocolArtNet, 0, []byte{10, 20, 30, 255, 3, 200}, []string{"color", "speed", "count", "randomDecay"}},
+		// DMX data is sent continuously, but only changes are published
+		{"nothing changed", hardware.ProtocolArtNet, 0, []byte{10, 20, 30, 255, 3, 200}, nil},
+		{"one changed", hardware.ProtocolArtNet, 0, []byte{10, 20, 30, 255, 4, 200}, []string{"count"}},
+		// the color is not complete, the other parameters are not sent
+		{"short data", hardware.ProtocolArtNet, 0, []byte{0, 0}, nil},
+		{"other universe", hardware.ProtocolArtNet, 1, []byte{255}, nil},
+		{"other protocol", hardware.ProtocolSACN, 0, []byte{0, 0, 0, 0, 0, 0}, nil},
+		{"sACN", hardware.ProtocolSACN, 1, []byte{255}, []string{"reversed"}},
+	}
+
+	for _, test := range tests {
+		input.handleDMX(test.protocol, test.universe, test.data)
+
+		changed := changedParameters(t, input.eventhub, client)
+		if len(changed) != len(test.changed) {
+			t.Errorf("%s: %d parameters changed, expected %d", test.name, len(changed), len(test.changed))
+			continue
+		}
+		for i, key := range test.changed {
+			if changed[i] != params[key] {
+				t.Errorf("%s: event %d is for %s, expected %s", test.name, i, changed[i].Key, key)
+			}
+		}
+	}
+
+	expected := map[string]interface{}{
+		"color":       color.NRGBA{R: 10, G: 20, B: 30, A: 255},
+		"speed":       100,
+		"count":       4,
+		"randomDecay": true,
+		"reversed":    true,
+	}
+	for key, value := range expected {
+		if params[key].Get() != value {
+			t.Errorf("%s is %v, expected %v", key, params[key].Get(), value)
+		}
+	}
+}
+
+func TestMappingNotSaved(t *testing.T) {
+	input, params := newTestInput(t)
+
+	mapping, err := input.NewMapping(Mapping{Protocol: hardware.ProtocolArtNet, Channel: 1, Parameter: params["speed"].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a directory with the name of the config file cannot be written
+	file := path.Join(viper.GetString("directories.config"), configName+".json")
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(file, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := input.NewMapping(Mapping{Protocol: hardware.ProtocolArtNet, Channel: 2, Parameter: params["count"].ID}); !errors.Is(err, ErrNotSaved) {
+		t.Errorf("NewMapping returned %v, expected ErrNotSaved", err)
+	}
+
+	if _, err := input.UpdateMapping(mapping, Mapping{Protocol: hardware.ProtocolArtNet, Channel: 3, Parameter: params["speed"].ID}); !errors.Is(err, ErrNotSaved) {
+		t.Errorf("UpdateMapping returned %v, expected ErrNotSaved", err)
+	}
+
+	if err := input.DeleteMapping(mapping); !errors.Is(err, ErrNotSaved) {
+		t.Errorf("DeleteMapping returned %v, expected ErrNotSaved", err)
+	}
+
+	// nothing was changed
+	mappings := input.Mappings()
+	if len(mappings) != 1 || mappings[0] != *mapping {
+		t.Errorf("mappings changed to %v, expected only %v", mappings, *mapping)
+	}
+
+	unknown := Mapping{ID: uuid.New()}
+	if _, err := input.UpdateMapping(&unknown, *mapping); !errors.Is(err, ErrUnknownMapping) {
+		t.Errorf("UpdateMapping of an unknown mapping returned %v, expected ErrUnknownMapping", err)
+	}
+	if err := input.DeleteMapping(&unknown); !errors.Is(err, ErrUnknownMapping) {
+		t.Errorf("DeleteMapping of an unknown mapping returned %v, expected ErrUnknownMapping", err)
+	}
+}
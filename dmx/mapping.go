@@ -0,0 +1,72 @@
+package dmx
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/google/uuid"
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// booleanThreshold is the DMX value from which on a boolean parameter is true
+const booleanThreshold = 128
+
+// Mapping connects DMX channels of an universe with a parameter
+type Mapping struct {
+	// ID is the unique ID of the mapping
+	ID uuid.UUID `json:"id"`
+
+	// Protocol is either "artnet" or "sacn"
+	Protocol string `json:"protocol"`
+
+	// Universe is the DMX universe
+	Universe int `json:"universe"`
+
+	// Channel is the first DMX channel (1 - 512). Colors use three channels (RGB), all other types only one.
+	Channel int `json:"channel"`
+
+	// Parameter is the ID of the parameter that is controlled
+	Parameter uuid.UUID `json:"parameter"`
+}
+
+// validate checks that protocol, universe and channel are valid
+func (mapping *Mapping) validate() error {
+	if mapping.Protocol != hardware.ProtocolArtNet && mapping.Protocol != hardware.ProtocolSACN {
+		return errors.New("Invalid protocol")
+	}
+
+	if err := hardware.ValidateUniverse(mapping.Protocol, mapping.Universe); err != nil {
+		return err
+	}
+
+	if mapping.Channel < 1 || mapping.Channel > 512 {
+		return errors.New("Invalid channel")
+	}
+
+	return nil
+}
+
+// numChannels returns the number of DMX channels that are needed for the datatype
+func numChannels(datatype string) int {
+	if datatype == parameters.Color {
+		return 3
+	}
+	return 1
+}
+
+// convert returns the parameter value for the DMX values. The slice needs to contain `numChannels` values.
+func convert(datatype string, values []byte) (interface{}, error) {
+	switch datatype {
+	case parameters.Color:
+		return color.NRGBA{R: values[0], G: values[1], B: values[2], A: 255}, nil
+	case parameters.Percent:
+		return (int(values[0])*100 + 127) / 255, nil
+	case parameters.IntegerGreaterOrEqualZero:
+		return int(values[0]), nil
+	case parameters.Boolean:
+		return values[0] >= booleanThreshold, nil
+	default:
+		return nil, errors.New("Unsupported datatype: " + datatype)
+	}
+}
@@ -0,0 +1,93 @@
+package dmx
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		datatype string
+		values   []byte
+		expected interface{}
+	}{
+		{parameters.Color, []byte{1, 2, 3}, color.NRGBA{R: 1, G: 2, B: 3, A: 255}},
+		{parameters.Color, []byte{255, 0, 128}, color.NRGBA{R: 255, G: 0, B: 128, A: 255}},
+		{parameters.Percent, []byte{0}, 0},
+		// the percent values are rounded
+		{parameters.Percent, []byte{1}, 0},
+		{parameters.Percent, []byte{2}, 1},
+		{parameters.Percent, []byte{128}, 50},
+		{parameters.Percent, []byte{254}, 100},
+		{parameters.Percent, []byte{255}, 100},
+		{parameters.IntegerGreaterOrEqualZero, []byte{0}, 0},
+		{parameters.IntegerGreaterOrEqualZero, []byte{42}, 42},
+		{parameters.IntegerGreaterOrEqualZero, []byte{255}, 255},
+		{parameters.Boolean, []byte{0}, false},
+		{parameters.Boolean, []byte{booleanThreshold - 1}, false},
+		{parameters.Boolean, []byte{booleanThreshold}, true},
+		{parameters.Boolean, []byte{255}, true},
+	}
+
+	for _, test := range tests {
+		value, err := convert(test.datatype, test.values)
+		if err != nil {
+			t.Errorf("convert(%s, %v) failed: %s", test.datatype, test.values, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("convert(%s, %v) = %v, expected %v", test.datatype, test.values, value, test.expected)
+		}
+	}
+
+	if _, err := convert("unknown", []byte{0}); err == nil {
+		t.Error("convert accepted an unknown datatype")
+	}
+}
+
+func TestNumChannels(t *testing.T) {
+	tests := []struct {
+		datatype string
+		expected int
+	}{
+		{parameters.Color, 3},
+		{parameters.Percent, 1},
+		{parameters.IntegerGreaterOrEqualZero, 1},
+		{parameters.Boolean, 1},
+	}
+
+	for _, test := range tests {
+		if channels := numChannels(test.datatype); channels != test.expected {
+			t.Errorf("numChannels(%s) = %d, expected %d", test.datatype, channels, test.expected)
+		}
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping Mapping
+		valid   bool
+	}{
+		{"Art-Net", Mapping{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 1}, true},
+		{"sACN", Mapping{Protocol: hardware.ProtocolSACN, Universe: 1, Channel: 512}, true},
+		{"unknown protocol", Mapping{Protocol: "dmx", Universe: 1, Channel: 1}, false},
+		{"no protocol", Mapping{Universe: 1, Channel: 1}, false},
+		{"negative universe", Mapping{Protocol: hardware.ProtocolArtNet, Universe: -1, Channel: 1}, false},
+		{"sACN universe 0", Mapping{Protocol: hardware.ProtocolSACN, Universe: 0, Channel: 1}, false},
+		{"channel 0", Mapping{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 0}, false},
+		{"channel 513", Mapping{Protocol: hardware.ProtocolArtNet, Universe: 0, Channel: 513}, false},
+	}
+
+	for _, test := range tests {
+		err := test.mapping.validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: valid mapping was rejected: %s", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: invalid mapping was accepted", test.name)
+		}
+	}
+}
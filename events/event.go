@@ -46,6 +46,12 @@ const (
 
 	// CurrentChanged is the event topic when the current show or visual were changed
 	CurrentChanged = "current_changed"
+
+	// PartsChanged is the event topic when the LED parts were reloaded
+	PartsChanged = "parts_changed"
+
+	// EffectFailed is the event topic when an effect panicked and was disabled
+	EffectFailed = "effect_failed"
 )
 
 // EventMetaInfo stores meta information about the event
@@ -0,0 +1,188 @@
+package hardware
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"github.com/spf13/viper"
+
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/devices/v3/apa102"
+	"periph.io/x/host/v3"
+)
+
+// apa102Driver sends the frames to APA102 LED stripes connected via SPI
+type apa102Driver struct {
+	spi    spi.PortCloser
+	device *apa102.Dev
+
+	spiPort   string
+	spiKHz    int
+	dithering bool
+
+	// without `leds.gamma` in the config, the perceptual mapping of the periph driver is used like in old versions
+	perceptual bool
+
+	// used instead of device if dithering is enabled
+	conn     spi.Conn
+	buffer   []byte
+	residual []float64
+}
+
+// newAPA102Driver creates a new APA102 driver. It uses `spiPort` (default: first port), `spiKHz` and `dithering` from
+// the config.
+func newAPA102Driver(config *viper.Viper) OutputDriver {
+	return &apa102Driver{
+		spiPort:    config.GetString("spiPort"),
+		spiKHz:     config.GetInt("spiKHz"),
+		dithering:  config.GetBool("dithering"),
+		perceptual: !viper.IsSet("leds.gamma"),
+	}
+}
+
+// Open initializes the SPI port and the APA102 LEDs
+func (driver *apa102Driver) Open(numLeds int) error {
+	// initialize periph library
+	if _, err := host.Init(); err != nil {
+		return err
+	}
+
+	// initialize SPI
+	spiConn, err := spireg.Open(driver.spiPort)
+	if err != nil {
+		return err
+	}
+
+	if driver.spiKHz > 0 {
+		spiConn.LimitSpeed(physic.Frequency(driver.spiKHz) * physic.KiloHertz)
+	}
+
+	if driver.dithering {
+		return driver.openDithering(spiConn, numLeds)
+	}
+
+	// initialize apa102: gamma correction and white balance are done by the LED output stage, so the colors are
+	// passed through without the perceptual mapping and temperature correction of the periph driver. Without a gamma
+	// value in the config, the driver keeps its default options, so that the LEDs look like before.
+	opts := apa102.PassThruOpts
+	if driver.perceptual {
+		opts = apa102.DefaultOpts
+	}
+	opts.NumPixels = numLeds
+	device, err := apa102.New(spiConn, &opts)
+	if err != nil {
+		spiConn.Close()
+		return err
+	}
+
+	driver.spi = spiConn
+	driver.device = device
+	return nil
+}
+
+// Draw sends the frame to the LEDs
+func (driver *apa102Driver) Draw(frame *image.NRGBA) error {
+	return driver.device.Draw(driver.device.Bounds(), frame, image.Point{})
+}
+
+// Close turns the LEDs off and closes the SPI port
+func (driver *apa102Driver) Close() error {
+	if driver.spi == nil {
+		return nil
+	}
+
+	if driver.dithering {
+		for i := range driver.residual {
+			driver.residual[i] = 0
+		}
+		driver.DrawHighDepth(image.NewNRGBA64(image.Rect(0, 0, len(driver.residual)/3, 1)))
+	} else {
+		driver.device.Halt()
+	}
+
+	err := driver.spi.Close()
+	driver.spi = nil
+	driver.conn = nil
+	return err
+}
+
+// Capabilities returns the features of the APA102 driver
+func (driver *apa102Driver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{Physical: true, HighDepth: driver.dithering}
+}
+
+// openDithering connects to the SPI port without the periph driver, so that the global brightness of every LED can be
+// set
+func (driver *apa102Driver) openDithering(spiConn spi.PortCloser, numLeds int) error {
+	conn, err := spiConn.Connect(20*physic.MegaHertz, spi.Mode3, 8)
+	if err != nil {
+		spiConn.Close()
+		return err
+	}
+
+	// start frame (zeros), four bytes per LED and end frame (ones) with enough clock cycles for all LEDs
+	driver.buffer = make([]byte, 4*(numLeds+1)+numLeds/16+1)
+	for i := 4 + 4*numLeds; i < len(driver.buffer); i++ {
+		driver.buffer[i] = 0xFF
+	}
+	driver.residual = make([]float64, 3*numLeds)
+
+	driver.spi = spiConn
+	driver.conn = conn
+	return nil
+}
+
+// DrawHighDepth sends the frame with 16 bits per channel to the LEDs using the global brightness and temporal
+// dithering
+func (driver *apa102Driver) DrawHighDepth(frame *image.NRGBA64) error {
+	if driver.conn == nil {
+		return errors.New("LED driver is not open")
+	}
+
+	encodeAPA102Dithered(driver.buffer[4:4+len(driver.residual)/3*4], frame, driver.residual)
+	return driver.conn.Tx(driver.buffer, nil)
+}
+
+// encodeAPA102Dithered writes the LED words (global brightness, blue, green, red) for the frame into `dst`. The 5 bit
+// global brightness of every LED is chosen as low as possible, so that dark colors get the full 8 bit resolution of
+// the channels. What is lost by rounding is kept in `residual` (three values per LED) and added to the next frame,
+// so that values between two steps are reached on average over time.
+func encodeAPA102Dithered(dst []byte, frame *image.NRGBA64, residual []float64) {
+	numLeds := frame.Bounds().Dx()
+
+	for i := 0; i < numLeds; i++ {
+		pixel := frame.NRGBA64At(frame.Bounds().Min.X+i, frame.Bounds().Min.Y)
+
+		// target values including the error of the last frames
+		target := [3]float64{
+			float64(pixel.R) + residual[3*i],
+			float64(pixel.G) + residual[3*i+1],
+			float64(pixel.B) + residual[3*i+2],
+		}
+		max := 0.0
+		for c := range target {
+			target[c] = math.Min(math.Max(target[c], 0), 65535)
+			max = math.Max(max, target[c])
+		}
+
+		// lowest brightness that can show the brightest channel, one step of a channel is `step` in 16 bit
+		brightness := int(math.Ceil(max * 31 / 65535))
+		if brightness < 1 {
+			brightness = 1
+		}
+		step := float64(brightness) * 257 / 31
+
+		var channels [3]byte
+		for c := range target {
+			value := math.Min(math.Round(target[c]/step), 255)
+			channels[c] = byte(value)
+			residual[3*i+c] = target[c] - value*step
+		}
+
+		dst[4*i] = 0xE0 | byte(brightness)
+		dst[4*i+1], dst[4*i+2], dst[4*i+3] = channels[2], channels[1], channels[0]
+	}
+}
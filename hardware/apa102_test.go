@@ -0,0 +1,103 @@
+package hardware
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"periph.io/x/conn/v3/spi/spitest"
+)
+
+func TestEncodeAPA102Dithered(t *testing.T) {
+	tests := []struct {
+		pixel    color.NRGBA64
+		expected []byte
+	}{
+		// full brightness uses the whole global brightness
+		{color.NRGBA64{R: 0xFFFF, G: 0xFFFF, B: 0xFFFF, A: 0xFFFF}, []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		// off still needs a brightness of at least 1
+		{color.NRGBA64{A: 0xFFFF}, []byte{0xE1, 0x00, 0x00, 0x00}},
+		// half brightness: global brightness 16, so that red gets almost the full range
+		{color.NRGBA64{R: 0x8000, A: 0xFFFF}, []byte{0xF0, 0x00, 0x00, 0xF7}},
+		// dark colors get the lowest global brightness, the order is blue, green, red
+		{color.NRGBA64{R: 1000, B: 500, A: 0xFFFF}, []byte{0xE1, 0x3C, 0x00, 0x79}},
+	}
+
+	for _, test := range tests {
+		frame := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+		frame.SetNRGBA64(0, 0, test.pixel)
+
+		dst := make([]byte, 4)
+		encodeAPA102Dithered(dst, frame, make([]float64, 3))
+		if !bytes.Equal(dst, test.expected) {
+			t.Errorf("encodeAPA102Dithered(%v) = % X, expected % X", test.pixel, dst, test.expected)
+		}
+	}
+}
+
+func TestEncodeAPA102DitheredResidual(t *testing.T) {
+	// red is about half of the smallest step with global brightness 1
+	frame := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+	frame.SetNRGBA64(0, 0, color.NRGBA64{R: 4, A: 0xFFFF})
+
+	dst := make([]byte, 4)
+	residual := make([]float64, 3)
+
+	// the first frame is rounded down, the error is carried to the next frame which is rounded up then
+	encodeAPA102Dithered(dst, frame, residual)
+	if dst[3] != 0 || residual[0] != 4 {
+		t.Errorf("first frame: red = %d, residual = %f, expected 0 and 4", dst[3], residual[0])
+	}
+
+	encodeAPA102Dithered(dst, frame, residual)
+	if dst[3] != 1 {
+		t.Errorf("second frame: red = %d, expected 1", dst[3])
+	}
+
+	// on average, the target value is reached
+	step := 257.0 / 31.0
+	sum := float64(dst[3])
+	for i := 2; i < 100; i++ {
+		encodeAPA102Dithered(dst, frame, residual)
+		sum += float64(dst[3])
+	}
+	if average := sum * step / 100; math.Abs(average-4) > step/100 {
+		t.Errorf("average red over 100 frames = %f, expected 4", average)
+	}
+}
+
+func TestAPA102DrawHighDepth(t *testing.T) {
+	port := &spitest.Record{}
+	driver := &apa102Driver{dithering: true}
+	if err := driver.openDithering(port, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := image.NewNRGBA64(image.Rect(0, 0, 2, 1))
+	frame.SetNRGBA64(0, 0, color.NRGBA64{R: 0xFFFF, G: 0xFFFF, B: 0xFFFF, A: 0xFFFF})
+	if err := driver.DrawHighDepth(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	// start frame, two LEDs and the end frame
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0xE1, 0x00, 0x00, 0x00,
+		0xFF,
+	}
+	if len(port.Ops) != 1 || !bytes.Equal(port.Ops[0].W, expected) {
+		t.Fatalf("DrawHighDepth sent %v, expected % X", port.Ops, expected)
+	}
+
+	// closing turns all LEDs off
+	if err := driver.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected[4], expected[5], expected[6], expected[7] = 0xE1, 0x00, 0x00, 0x00
+	if len(port.Ops) != 2 || !bytes.Equal(port.Ops[1].W, expected) {
+		t.Errorf("Close sent %v, expected % X", port.Ops, expected)
+	}
+}
@@ -0,0 +1,66 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// ArtNetPort is the UDP port for Art-Net
+	ArtNetPort = 6454
+
+	// artNetOpDmx is the opcode of an ArtDmx packet
+	artNetOpDmx = 0x5000
+
+	// artNetProtocolVersion is the Art-Net protocol revision
+	artNetProtocolVersion = 14
+
+	// artNetHeaderSize is the size of the ArtDmx header in front of the DMX data
+	artNetHeaderSize = 18
+
+	// artNetMaxUniverse is the highest universe (15 bit port address)
+	artNetMaxUniverse = 0x7fff
+)
+
+var artNetID = []byte{'A', 'r', 't', '-', 'N', 'e', 't', 0}
+
+// encodeArtDmx builds an ArtDmx packet for `universe` (15 bit port address) with the DMX `data` (at most 512 bytes)
+func encodeArtDmx(universe int, sequence byte, data []byte) []byte {
+	// the length needs to be even
+	length := len(data)
+	if length%2 != 0 {
+		length++
+	}
+
+	packet := make([]byte, artNetHeaderSize+length)
+	copy(packet[0:8], artNetID)
+	binary.LittleEndian.PutUint16(packet[8:10], artNetOpDmx)
+	binary.BigEndian.PutUint16(packet[10:12], artNetProtocolVersion)
+	packet[12] = sequence
+	packet[13] = 0                        // physical port
+	packet[14] = byte(universe & 0xff)    // sub-net and universe
+	packet[15] = byte(universe>>8) & 0x7f // net
+	binary.BigEndian.PutUint16(packet[16:18], uint16(length))
+	copy(packet[artNetHeaderSize:], data)
+
+	return packet
+}
+
+// decodeArtDmx parses an ArtDmx packet and returns the universe and the DMX data
+func decodeArtDmx(packet []byte) (int, []byte, error) {
+	if len(packet) < artNetHeaderSize || string(packet[0:8]) != string(artNetID) {
+		return 0, nil, errors.New("Not an Art-Net packet")
+	}
+
+	if binary.LittleEndian.Uint16(packet[8:10]) != artNetOpDmx {
+		return 0, nil, errors.New("Not an ArtDmx packet")
+	}
+
+	universe := int(packet[15]&0x7f)<<8 | int(packet[14])
+	length := int(binary.BigEndian.Uint16(packet[16:18]))
+	if length > dmxUniverseSize || artNetHeaderSize+length > len(packet) {
+		return 0, nil, errors.New("Invalid length of ArtDmx packet")
+	}
+
+	return universe, packet[artNetHeaderSize : artNetHeaderSize+length], nil
+}
@@ -0,0 +1,43 @@
+package hardware
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultColorOrder is the color order that is expected by the output drivers
+const DefaultColorOrder = "RGB"
+
+// colorOrder contains for every channel that is sent to the driver the index of the logical channel (0: R, 1: G, 2: B)
+type colorOrder [3]int
+
+// identityColorOrder does not change the channels
+var identityColorOrder = colorOrder{0, 1, 2}
+
+// parseColorOrder converts a color order like "GRB" to the channel mapping
+func parseColorOrder(order string) (colorOrder, error) {
+	order = strings.ToUpper(order)
+	if len(order) != 3 {
+		return colorOrder{}, errors.New("Invalid color order: " + order)
+	}
+
+	result := colorOrder{}
+	used := [3]bool{}
+	for i, channel := range order {
+		index := strings.IndexRune(DefaultColorOrder, channel)
+		if index < 0 || used[index] {
+			return colorOrder{}, errors.New("Invalid color order: " + order)
+		}
+
+		result[i] = index
+		used[index] = true
+	}
+
+	return result, nil
+}
+
+// swizzle reorders the channels
+func (order colorOrder) swizzle(r uint16, g uint16, b uint16) (uint16, uint16, uint16) {
+	channels := [3]uint16{r, g, b}
+	return channels[order[0]], channels[order[1]], channels[order[2]]
+}
@@ -0,0 +1,40 @@
+package hardware
+
+import (
+	"image"
+
+	"github.com/spf13/viper"
+
+	"periph.io/x/devices/v3/screen1d"
+)
+
+// consoleDriver prints the LEDs at the console. It is used if no real LEDs are connected.
+type consoleDriver struct {
+	screen *screen1d.Dev
+}
+
+// newConsoleDriver creates a new console driver
+func newConsoleDriver(config *viper.Viper) OutputDriver {
+	return &consoleDriver{}
+}
+
+// Open prepares the console output
+func (driver *consoleDriver) Open(numLeds int) error {
+	driver.screen = screen1d.New(&screen1d.Opts{X: numLeds})
+	return nil
+}
+
+// Draw prints the frame at the console
+func (driver *consoleDriver) Draw(frame *image.NRGBA) error {
+	return driver.screen.Draw(driver.screen.Bounds(), frame, image.Point{})
+}
+
+// Close does nothing for the console
+func (driver *consoleDriver) Close() error {
+	return nil
+}
+
+// Capabilities returns the features of the console driver
+func (driver *consoleDriver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{Physical: false}
+}
@@ -0,0 +1,62 @@
+package hardware
+
+import (
+	"errors"
+	"math"
+)
+
+// WhiteBalance contains the scaling factors (0 - 1) for red, green and blue
+type WhiteBalance [3]float64
+
+// NeutralWhiteBalance does not change the colors
+var NeutralWhiteBalance = WhiteBalance{1, 1, 1}
+
+// colorCorrection is a lookup table for gamma correction and white balance. The corrected values have 16 bits, so
+// that dark colors do not collapse to a few values before they reach drivers that can use more than 8 bits.
+type colorCorrection struct {
+	r [256]uint16
+	g [256]uint16
+	b [256]uint16
+}
+
+// newColorCorrection calculates the lookup table for the gamma value and white balance
+func newColorCorrection(gamma float64, balance WhiteBalance) *colorCorrection {
+	correction := colorCorrection{}
+
+	for i := 0; i < 256; i++ {
+		value := math.Pow(float64(i)/255, gamma)
+		correction.r[i] = uint16(math.Round(65535 * value * balance[0]))
+		correction.g[i] = uint16(math.Round(65535 * value * balance[1]))
+		correction.b[i] = uint16(math.Round(65535 * value * balance[2]))
+	}
+
+	return &correction
+}
+
+// apply returns the corrected color
+func (correction *colorCorrection) apply(r byte, g byte, b byte) (uint16, uint16, uint16) {
+	return correction.r[r], correction.g[g], correction.b[b]
+}
+
+// to8Bit rounds a 16 bit channel value to 8 bits
+func to8Bit(value uint16) byte {
+	return byte((uint32(value) + 128) / 257)
+}
+
+// validateGamma checks that the gamma value is usable
+func validateGamma(gamma float64) error {
+	if gamma <= 0 || gamma > 5 {
+		return errors.New("Gamma needs to be between 0 and 5")
+	}
+	return nil
+}
+
+// validateWhiteBalance checks that all factors are between 0 and 1
+func validateWhiteBalance(balance WhiteBalance) error {
+	for _, factor := range balance {
+		if factor < 0 || factor > 1 {
+			return errors.New("White balance factors need to be between 0 and 1")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package hardware
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"syscall"
+)
+
+// DMXHandler is called for every DMX universe that is received over the network
+type DMXHandler func(protocol string, universe int, data []byte)
+
+// DMXInput receives DMX data from lighting desks via Art-Net or sACN (E1.31)
+type DMXInput struct {
+	conns   map[string]*net.UDPConn
+	joined  map[int]bool
+	handler DMXHandler
+
+	mux sync.Mutex
+}
+
+// NewDMXInput creates a new DMX input. It does not listen until `Listen` is called.
+func NewDMXInput() *DMXInput {
+	return &DMXInput{
+		conns:  make(map[string]*net.UDPConn),
+		joined: make(map[int]bool),
+	}
+}
+
+// SetHandler sets the function that is called for received DMX data
+func (input *DMXInput) SetHandler(handler DMXHandler) {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	input.handler = handler
+}
+
+// Listen starts receiving DMX data with the given protocol ("artnet" or "sacn")
+func (input *DMXInput) Listen(protocol string) error {
+	var port int
+	switch protocol {
+	case ProtocolArtNet:
+		port = ArtNetPort
+	case ProtocolSACN:
+		port = SACNPort
+	default:
+		return errors.New("Unknown protocol: " + protocol)
+	}
+
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	if _, exists := input.conns[protocol]; exists {
+		return nil
+	}
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: port})
+	if err != nil {
+		return err
+	}
+	input.conns[protocol] = conn
+
+	go input.receive(protocol, conn)
+
+	return nil
+}
+
+// Listening returns whether DMX data is received with the given protocol
+func (input *DMXInput) Listening(protocol string) bool {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	_, exists := input.conns[protocol]
+	return exists
+}
+
+// JoinUniverse joins the sACN multicast group of the universe. It is not needed for unicast and Art-Net.
+func (input *DMXInput) JoinUniverse(universe int) error {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	conn, exists := input.conns[ProtocolSACN]
+	if !exists || input.joined[universe] {
+		return nil
+	}
+
+	if universe < sacnMinUniverse || universe > sacnMaxUniverse {
+		return errors.New("sACN universe out of range")
+	}
+
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	mreq := &syscall.IPMreq{}
+	copy(mreq.Multiaddr[:], sacnMulticastAddress(universe).To4())
+
+	var joinErr error
+	err = rawConn.Control(func(fd uintptr) {
+		joinErr = syscall.SetsockoptIPMreq(int(fd), syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, mreq)
+	})
+	if err != nil {
+		return err
+	}
+	if joinErr != nil {
+		return joinErr
+	}
+
+	input.joined[universe] = true
+	return nil
+}
+
+// Close stops receiving DMX data
+func (input *DMXInput) Close() {
+	input.mux.Lock()
+	defer input.mux.Unlock()
+
+	for protocol, conn := range input.conns {
+		conn.Close()
+		delete(input.conns, protocol)
+	}
+	input.joined = make(map[int]bool)
+}
+
+// receive reads the packets from the socket and calls the handler
+func (input *DMXInput) receive(protocol string, conn *net.UDPConn) {
+	buffer := make([]byte, 1024)
+
+	for {
+		n, _, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Print("Failed to receive DMX data: " + err.Error())
+			}
+			return
+		}
+
+		var universe int
+		var data []byte
+		if protocol == ProtocolArtNet {
+			universe, data, err = decodeArtDmx(buffer[:n])
+		} else {
+			universe, data, err = decodeSACN(buffer[:n])
+		}
+		if err != nil {
+			// other packet types (like ArtPoll) or invalid data
+			continue
+		}
+
+		input.mux.Lock()
+		handler := input.handler
+		input.mux.Unlock()
+
+		if handler != nil {
+			handler(protocol, universe, data)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package hardware
+
+import "image"
+
+// Frame is a copy of a complete frame as set by the effects (without gamma correction and white balance)
+type Frame struct {
+	*PartLayout
+	image *image.NRGBA
+}
+
+// GetColor returns the color of one pixel of the frame
+func (frame *Frame) GetColor(part string, pos int) (r byte, g byte, b byte) {
+	ledID := frame.mapLedPartPos(part, pos)
+
+	color := frame.image.NRGBAAt(ledID, 0)
+	return color.R, color.G, color.B
+}
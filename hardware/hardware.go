@@ -2,68 +2,445 @@ package hardware
 
 import (
 	"errors"
+	"log"
+	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 )
 
 // Hardware controlls all connected hardware like LEDs, the ethernet interface or the controller board itself.
 type Hardware struct {
-	Led    *LED
-	System *System
+	Led      *LED
+	Network  *NetworkOutput
+	DMXInput *DMXInput
+	Recorder *Recorder
+	Player   *Player
+	System   *System
+
+	frameMux sync.Mutex
+
+	// part definitions from the config file and from the part editor that replace the ones from the config file (or nil)
+	partConfig  *ledsFormat
+	definitions []PartDefinition
+	partsMux    sync.Mutex
+}
+
+// segmentFormat is a line segment with LEDs in the config file
+type segmentFormat struct {
+	Start []float64 `mapstructure:"start"`
+	End   []float64 `mapstructure:"end"`
+	Leds  int       `mapstructure:"leds"`
+}
+
+// partFormat is the definition of a part in the config file
+type partFormat struct {
+	Name         string          `mapstructure:"name"`
+	Output       string          `mapstructure:"output"`
+	Leds         [][]int         `mapstructure:"leds"`
+	ColorOrder   string          `mapstructure:"colorOrder"`
+	WhiteBalance []float64       `mapstructure:"whiteBalance"`
+	Positions    [][]float64     `mapstructure:"positions"`
+	Segments     []segmentFormat `mapstructure:"segments"`
+	Network      []NetworkTarget `mapstructure:"network"`
+}
+
+// zoneFormat is the definition of a zone in the config file
+type zoneFormat struct {
+	Name  string   `mapstructure:"name"`
+	Parts []string `mapstructure:"parts"`
+}
+
+// outputFormat is a physical LED output in the config file, all other settings are passed to the driver
+type outputFormat struct {
+	Name     string                 `mapstructure:"name"`
+	Driver   string                 `mapstructure:"driver"`
+	Leds     int                    `mapstructure:"leds"`
+	Settings map[string]interface{} `mapstructure:",remain"`
+}
+
+// ledsFormat is the LED section of the config file
+type ledsFormat struct {
+	Driver  string         `mapstructure:"driver"`
+	Count   int            `mapstructure:"count"`
+	Outputs []outputFormat `mapstructure:"outputs"`
+	Parts   []partFormat   `mapstructure:"parts"`
+	Zones   []zoneFormat   `mapstructure:"zones"`
+
+	// all settings of the LED section, the driver settings are taken from there
+	config *viper.Viper
 }
 
 // New initializes the hardware
 func New() (*Hardware, error) {
+	return newHardware("")
+}
+
+// NewSimulated initializes the hardware like New, but the colors of the LEDs are only kept in memory
+func NewSimulated() (*Hardware, error) {
+	return newHardware(DriverMemory)
+}
+
+// newHardware initializes the hardware with the given LED driver for all outputs (or the configured drivers if empty)
+func newHardware(driverName string) (*Hardware, error) {
+	hw, partConfig, err := newWithLED(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// configure network output for parts
+	hw.Network, err = newNetworkOutputFromConfig(partConfig, hw.Led.Layout())
+	if err != nil {
+		return nil, err
+	}
+
+	// receive DMX data from lighting desks
+	hw.DMXInput = NewDMXInput()
+	for _, protocol := range []string{ProtocolArtNet, ProtocolSACN} {
+		if viper.GetBool("dmxInput." + protocol) {
+			if err := hw.DMXInput.Listen(protocol); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// system
+	hw.System = NewSystem()
+
+	// finished
+	return hw, nil
+}
+
+// NewOffline initializes the configured LED parts without connecting to any hardware. The colors are only kept in
+// memory, which is used to render visuals.
+func NewOffline() (*Hardware, error) {
+	hw, _, err := newWithLED(DriverMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	hw.Network = NewNetworkOutput()
+	hw.DMXInput = NewDMXInput()
+
+	return hw, nil
+}
+
+// newWithLED reads the part definition from the config file and initializes the LEDs with the given driver (or the
+// configured drivers if empty)
+func newWithLED(driverName string) (*Hardware, *ledsFormat, error) {
 	hw := Hardware{}
 
-	// create leds struct
-	hw.Led = NewLED()
+	partConfig, err := readPartConfig(viper.Sub("leds"))
+	if err != nil {
+		return nil, nil, err
+	}
+	hw.partConfig = partConfig
 
-	// read led parts from config file
-	ledsConfig := viper.Sub("leds")
-	if ledsConfig == nil {
-		return nil, errors.New("Missing LED part definition") // should not happen since it is added by default. but who knows ;)
+	layout, err := loadPartLayout(partConfig, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	type partFormat struct {
-		Name string  `mapstructure:"name"`
-		Leds [][]int `mapstructure:"leds"`
+	// create and init leds
+	hw.Led = NewLED()
+	hw.Led.SetPartLayout(layout)
+	if err := hw.Led.InitWithDriver(driverName); err != nil {
+		return nil, nil, err
 	}
 
-	type format struct {
-		Parts []partFormat `mapstructure:"parts"`
+	// recording and playback of frames
+	hw.Recorder = NewRecorder()
+	hw.Player = NewPlayer()
+
+	return &hw, partConfig, nil
+}
+
+// readPartConfig reads the part definition from the LED section of the config file
+func readPartConfig(ledsConfig *viper.Viper) (*ledsFormat, error) {
+	if ledsConfig == nil {
+		return nil, errors.New("Missing LED part definition") // should not happen since it is added by default. but who knows ;)
 	}
-	var partConfig format
+
+	var partConfig ledsFormat
 
 	err := ledsConfig.Unmarshal(&partConfig)
 	if err != nil {
 		return nil, errors.New("Malformed LED part definition")
 	}
+	partConfig.config = ledsConfig
+
+	return &partConfig, nil
+}
+
+// loadPartLayout creates the part layout from the config file. If `definitions` is not nil, the names and LED ranges
+// of the parts are taken from there and the config file only provides the other settings of the parts.
+func loadPartLayout(partConfig *ledsFormat, definitions []PartDefinition) (*PartLayout, error) {
+	layout := NewPartLayout()
 
 	// configure led parts
+	if definitions == nil {
+		definitions = partConfig.definitions()
+	}
+
+	if len(partConfig.Outputs) == 0 {
+		// only one output, the LED IDs are counted over all parts
+		for _, part := range definitions {
+			if part.Output != "" {
+				return nil, errors.New("Unknown output " + part.Output + " for part " + part.Name)
+			}
+
+			for _, leds := range part.Leds {
+				if len(leds) != 2 {
+					return nil, errors.New("Malformed LED part definition")
+				}
+
+				layout.AddPart(part.Name, leds[0], leds[1])
+			}
+		}
+
+		layout.AddOutput("", partConfig.Driver, outputConfig(partConfig.config, nil), layout.getTotalNumLeds())
+	} else {
+		// multiple outputs, the LED IDs start at 0 for every output
+		for _, output := range partConfig.Outputs {
+			config := outputConfig(partConfig.config, output.Settings)
+
+			if err := layout.AddOutput(output.Name, output.Driver, config, partConfig.outputNumLeds(output.Name)); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, part := range definitions {
+			if part.Output == "" {
+				return nil, errors.New("Missing output for part " + part.Name)
+			}
+
+			for _, leds := range part.Leds {
+				if len(leds) != 2 {
+					return nil, errors.New("Malformed LED part definition")
+				}
+
+				if err := layout.AddPartToOutput(part.Name, part.Output, leds[0], leds[1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// zones
+	for _, zone := range partConfig.Zones {
+		if err := layout.AddZone(zone.Name, zone.Parts); err != nil {
+			return nil, errors.New("Invalid zone " + zone.Name + ": " + err.Error())
+		}
+	}
+
+	// color order and white balance of parts
 	for _, part := range partConfig.Parts {
-		for _, leds := range part.Leds {
-			if len(leds) != 2 {
-				return nil, errors.New("Malformed LED part definition")
+		if !layout.HasPart(part.Name) {
+			continue
+		}
+
+		if part.ColorOrder != "" {
+			if err := layout.SetColorOrder(part.Name, part.ColorOrder); err != nil {
+				return nil, errors.New("Invalid color order for part " + part.Name)
+			}
+		}
+
+		if part.WhiteBalance == nil {
+			continue
+		}
+
+		if len(part.WhiteBalance) != 3 {
+			return nil, errors.New("Malformed white balance for part " + part.Name)
+		}
+
+		err := layout.SetWhiteBalance(part.Name, WhiteBalance{part.WhiteBalance[0], part.WhiteBalance[1], part.WhiteBalance[2]})
+		if err != nil {
+			return nil, errors.New("Invalid white balance for part " + part.Name + ": " + err.Error())
+		}
+	}
+
+	// positions of the LEDs: either an explicit list or line segments
+	for _, part := range partConfig.Parts {
+		if !layout.HasPart(part.Name) {
+			continue
+		}
+
+		var positions []Position
+
+		for _, coordinates := range part.Positions {
+			position, err := NewPosition(coordinates)
+			if err != nil {
+				return nil, errors.New("Malformed position for part " + part.Name + ": " + err.Error())
+			}
+			positions = append(positions, position)
+		}
+
+		for _, segment := range part.Segments {
+			start, err := NewPosition(segment.Start)
+			if err != nil {
+				return nil, errors.New("Malformed segment for part " + part.Name + ": " + err.Error())
+			}
+			end, err := NewPosition(segment.End)
+			if err != nil {
+				return nil, errors.New("Malformed segment for part " + part.Name + ": " + err.Error())
+			}
+
+			// a single segment without number of LEDs covers the whole part
+			count := segment.Leds
+			if count == 0 && len(part.Segments) == 1 && len(part.Positions) == 0 {
+				count = layout.GetNumLeds(part.Name)
 			}
 
-			hw.Led.AddPart(part.Name, leds[0], leds[1])
+			positions = append(positions, InterpolateSegment(start, end, count)...)
+		}
+
+		if positions == nil {
+			continue
+		}
+
+		if err := layout.SetPositions(part.Name, positions); err != nil {
+			return nil, errors.New("Invalid positions for part " + part.Name + ": " + err.Error())
+		}
+	}
+
+	return layout, nil
+}
+
+// newNetworkOutputFromConfig creates and opens the network output for the parts
+func newNetworkOutputFromConfig(partConfig *ledsFormat, layout *PartLayout) (*NetworkOutput, error) {
+	output := NewNetworkOutput()
+	for _, part := range partConfig.Parts {
+		if !layout.HasPart(part.Name) {
+			continue
+		}
+
+		for _, target := range part.Network {
+			err := output.AddTarget(part.Name, layout.GetNumLeds(part.Name), target)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// init leds
-	if err := hw.Led.Init(); err != nil {
+	if err := output.Open(); err != nil {
 		return nil, err
 	}
 
-	// system
-	hw.System = NewSystem()
+	return output, nil
+}
 
-	// finished
-	return &hw, nil
+// Lock needs to be held while a frame is drawn and written to the hardware, so that the part layout does not change
+// in between
+func (hw *Hardware) Lock() {
+	hw.frameMux.Lock()
+}
+
+// Unlock releases the lock that is held while a frame is drawn
+func (hw *Hardware) Unlock() {
+	hw.frameMux.Unlock()
 }
 
-// Update writes changes to the hardware
-func (hw *Hardware) Update() {
-	hw.Led.Update()
+// ReloadParts reads the part definition from the config file again and uses it from the next frame on. Parts from the
+// part editor are kept. `check` can reject the new layout, e.g. if parts are missing that are still in use. It is
+// called with the lock of LockParts held, so code that changes the parts in use needs to hold it too. Only the LED
+// section is read again, all other settings keep the values from the start.
+func (hw *Hardware) ReloadParts(check func(layout *PartLayout) error) error {
+	hw.partsMux.Lock()
+	defer hw.partsMux.Unlock()
+
+	// the global config is read by other goroutines, so the file is read into a new instance
+	config := viper.New()
+	config.SetConfigFile(viper.ConfigFileUsed())
+	if err := config.ReadInConfig(); err != nil {
+		return errors.New("Cannot read config file: " + err.Error())
+	}
+
+	partConfig, err := readPartConfig(config.Sub("leds"))
+	if err != nil {
+		return err
+	}
+
+	if hw.definitions != nil {
+		if err := validatePartDefinitions(hw.definitions, partConfig); err != nil {
+			return err
+		}
+	}
+
+	layout, err := loadPartLayout(partConfig, hw.definitions)
+	if err != nil {
+		return err
+	}
+
+	if err := check(layout); err != nil {
+		return err
+	}
+
+	network, err := newNetworkOutputFromConfig(partConfig, layout)
+	if err != nil {
+		return err
+	}
+
+	if err := hw.applyPartLayout(layout, network, nil); err != nil {
+		return err
+	}
+
+	hw.partConfig = partConfig
+	return nil
+}
+
+// applyPartLayout swaps the layout and the network output for it between two frames. `apply` is called right after
+// the swap while the next frame still waits.
+func (hw *Hardware) applyPartLayout(layout *PartLayout, network *NetworkOutput, apply func(layout *PartLayout)) error {
+	hw.Lock()
+	defer hw.Unlock()
+
+	if err := hw.Led.SetPartLayout(layout); err != nil {
+		network.Close()
+		return err
+	}
+
+	hw.Network.Close()
+	hw.Network = network
+
+	if apply != nil {
+		apply(layout)
+	}
+
+	return nil
+}
+
+// Update writes changes to the hardware. All outputs are updated even if one of them fails, the errors of the LED and
+// the network outputs are returned together.
+func (hw *Hardware) Update() error {
+	if err := hw.Recorder.WriteFrame(hw.Led); err != nil {
+		log.Print("Failed to write recording, stopping it: " + err.Error())
+		hw.Recorder.Stop()
+	}
+
+	ledErr := hw.Led.Update()
+	networkErr := hw.Network.Update(hw.Led)
+	return errors.Join(ledErr, networkErr)
+}
+
+// outputConfig returns the driver settings of an output. The settings in `ledsConfig` (like `spiKHz` or `dithering`)
+// are used for all outputs, an output can override them with `settings`.
+func outputConfig(ledsConfig *viper.Viper, settings map[string]interface{}) *viper.Viper {
+	config := viper.New()
+
+	if ledsConfig != nil {
+		for _, key := range ledsConfig.AllKeys() {
+			// the layout is not a driver setting
+			switch strings.SplitN(key, ".", 2)[0] {
+			case "parts", "outputs", "zones":
+				continue
+			}
+			config.Set(key, ledsConfig.Get(key))
+		}
+	}
+
+	for key, value := range settings {
+		config.Set(key, value)
+	}
+
+	return config
 }
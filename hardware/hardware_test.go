@@ -0,0 +1,91 @@
+package hardware
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfig writes a config file with the part "part" that has `numLeds` LEDs
+func writeConfig(t *testing.T, file string, numLeds int, password string) {
+	config := "api:\n" +
+		"  authentication: " + password + "\n" +
+		"leds:\n" +
+		"  driver: memory\n" +
+		"  parts:\n" +
+		"    - name: part\n" +
+		"      leds: [[0, " + strconv.Itoa(numLeds-1) + "]]\n"
+
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newConfiguredHardware reads the config file into the global config and initializes the LEDs in memory
+func newConfiguredHardware(t *testing.T, file string) *Hardware {
+	viper.SetConfigFile(file)
+	t.Cleanup(viper.Reset)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	hw, err := NewOffline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hw
+}
+
+func TestReloadParts(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, 5, "old")
+	hw := newConfiguredHardware(t, file)
+
+	writeConfig(t, file, 8, "new")
+	if err := hw.ReloadParts(func(layout *PartLayout) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if numLeds := hw.Led.GetNumLeds("part"); numLeds != 8 {
+		t.Errorf("part has %d LEDs after reload, expected 8", numLeds)
+	}
+	if password := viper.GetString("api.authentication"); password != "old" {
+		t.Errorf("other settings were reloaded too, password is %s", password)
+	}
+}
+
+func TestSameOutputs(t *testing.T) {
+	newOutputs := func(spiKHz int) []*layoutOutput {
+		return []*layoutOutput{{name: "a", driver: DriverWS2812, config: outputConfig(nil, map[string]interface{}{"spiKHz": spiKHz}), numLeds: 10}}
+	}
+
+	if !sameOutputs(newOutputs(2400), newOutputs(2400)) {
+		t.Error("outputs with the same settings are different")
+	}
+	if sameOutputs(newOutputs(2400), newOutputs(3200)) {
+		t.Error("outputs with different driver settings are the same")
+	}
+}
+
+func TestSetPartDefinitionsSaveFails(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, 5, "")
+	hw := newConfiguredHardware(t, file)
+
+	hw.LockParts()
+	defer hw.UnlockParts()
+
+	definitions := []PartDefinition{{Name: "renamed", Leds: [][]int{{0, 4}}}}
+	save := func() error { return errors.New("Disk full") }
+	if err := hw.SetPartDefinitions(definitions, save, nil); err == nil {
+		t.Fatal("failed save was not reported")
+	}
+
+	if !hw.Led.HasPart("part") || hw.Led.HasPart("renamed") {
+		t.Error("parts were changed although they could not be saved")
+	}
+}
@@ -0,0 +1,24 @@
+package hardware
+
+import "testing"
+
+// newMemoryLED returns LEDs in memory with the part "part" that has `numLeds` LEDs
+func newMemoryLED(t *testing.T, numLeds int) *LED {
+	layout := NewPartLayout()
+	layout.AddPart("part", 0, numLeds-1)
+
+	return newMemoryLEDWithLayout(t, layout)
+}
+
+// newMemoryLEDWithLayout returns LEDs in memory with the given parts. The global config is not read, so there is no
+// gamma correction and the colors are sent unchanged.
+func newMemoryLEDWithLayout(t *testing.T, layout *PartLayout) *LED {
+	led := NewLED()
+	if err := led.SetPartLayout(layout); err != nil {
+		t.Fatal(err)
+	}
+	if err := led.InitWithDriver(DriverMemory); err != nil {
+		t.Fatal(err)
+	}
+	return led
+}
@@ -0,0 +1,260 @@
+package hardware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// PartLayout maps the parts to the LED IDs and contains the settings of the parts. After a layout was passed to
+// LED.SetPartLayout, it should not be changed anymore since it is read from multiple goroutines.
+type PartLayout struct {
+	parts      []string
+	partLedMap map[string][]int
+	partRanges map[string][][]int
+	maxLedID   int
+
+	outputs     []*layoutOutput
+	partOutputs map[string]string
+
+	zones     []string
+	zoneParts map[string][]string
+
+	whiteBalance  map[string]WhiteBalance
+	colorOrders   map[string]string
+	partPositions map[string][]Position
+}
+
+// NewPartLayout creates an empty part layout
+func NewPartLayout() *PartLayout {
+	layout := &PartLayout{}
+	layout.partLedMap = make(map[string][]int)
+	layout.partRanges = make(map[string][][]int)
+	layout.partOutputs = make(map[string]string)
+	layout.maxLedID = -1
+	layout.zoneParts = make(map[string][]string)
+	layout.whiteBalance = make(map[string]WhiteBalance)
+	layout.colorOrders = make(map[string]string)
+	layout.partPositions = make(map[string][]Position)
+	return layout
+}
+
+// layoutOutput is a physical LED output. Its LEDs follow the LEDs of the previous outputs in the frame.
+type layoutOutput struct {
+	name    string
+	driver  string
+	config  *viper.Viper
+	offset  int
+	numLeds int
+}
+
+// AddPart adds the LEDs from id `first` to id `last` to the part `name`. AddPart can be called multiple times per part.
+func (layout *PartLayout) AddPart(name string, first int, last int) {
+	layout.addLeds(name, first, last)
+	layout.partRanges[name] = append(layout.partRanges[name], []int{first, last})
+}
+
+// AddOutput adds a physical output with `numLeds` LEDs that is driven by `driver` with the settings in `config`.
+// The outputs are added one after the other to the frame.
+func (layout *PartLayout) AddOutput(name string, driver string, config *viper.Viper, numLeds int) error {
+	if layout.getOutput(name) != nil {
+		return errors.New("Duplicate output name " + name)
+	}
+
+	offset := 0
+	for _, output := range layout.outputs {
+		offset += output.numLeds
+	}
+
+	layout.outputs = append(layout.outputs, &layoutOutput{name: name, driver: driver, config: config, offset: offset, numLeds: numLeds})
+	return nil
+}
+
+// AddPartToOutput adds the LEDs from id `first` to id `last` of the output `output` to the part `name`. The IDs start
+// at 0 for every output. All LEDs of a part need to be on the same output.
+func (layout *PartLayout) AddPartToOutput(name string, output string, first int, last int) error {
+	out := layout.getOutput(output)
+	if out == nil {
+		return errors.New("Unknown output " + output)
+	}
+
+	for _, id := range []int{first, last} {
+		if id < 0 || id >= out.numLeds {
+			return errors.New("LED " + strconv.Itoa(id) + " of part " + name + " does not exist, output " + output + " has only " + strconv.Itoa(out.numLeds) + " LEDs")
+		}
+	}
+
+	if partOutput, exists := layout.partOutputs[name]; exists && partOutput != output {
+		return errors.New("All LEDs of part " + name + " need to be on the same output")
+	}
+
+	layout.addLeds(name, out.offset+first, out.offset+last)
+	layout.partRanges[name] = append(layout.partRanges[name], []int{first, last})
+	layout.partOutputs[name] = output
+	return nil
+}
+
+// GetOutputs returns the names of all outputs
+func (layout *PartLayout) GetOutputs() []string {
+	names := make([]string, 0, len(layout.outputs))
+	for _, output := range layout.outputs {
+		names = append(names, output.name)
+	}
+	return names
+}
+
+// GetOutput returns the output of a part or an empty string if the part was added without output
+func (layout *PartLayout) GetOutput(part string) string {
+	return layout.partOutputs[part]
+}
+
+// getOutput returns the output with the given name or nil
+func (layout *PartLayout) getOutput(name string) *layoutOutput {
+	for _, output := range layout.outputs {
+		if output.name == name {
+			return output
+		}
+	}
+	return nil
+}
+
+// addLeds adds the LEDs from id `first` to id `last` of the whole frame to the part `name`
+func (layout *PartLayout) addLeds(name string, first int, last int) {
+	// add to ordered list of part names if necessary
+	if !layout.HasPart(name) {
+		layout.parts = append(layout.parts, name)
+	}
+
+	// add to mapping
+	layout.partLedMap[name] = append(layout.partLedMap[name], getRange(first, last)...)
+
+	// update max led id
+	if last > layout.maxLedID {
+		layout.maxLedID = last
+	}
+	if first > layout.maxLedID {
+		layout.maxLedID = first
+	}
+}
+
+// GetParts returns the names of all parts
+func (layout *PartLayout) GetParts() []string {
+	return layout.parts
+}
+
+// HasPart checks if `part` is a valid part name
+func (layout *PartLayout) HasPart(part string) bool {
+	_, exists := layout.partLedMap[part]
+	return exists
+}
+
+// GetNumLeds returns the number of leds in a part
+func (layout *PartLayout) GetNumLeds(part string) int {
+	ledIDs, exists := layout.partLedMap[part]
+	if !exists {
+		panic("invalid part name")
+	}
+	return len(ledIDs)
+}
+
+// GetRanges returns the LED ranges (first and last LED ID) of a part in the order they were added
+func (layout *PartLayout) GetRanges(part string) [][]int {
+	return layout.partRanges[part]
+}
+
+// GetNumLedsMultiPart returns the number of leds of all specified parts and zones
+func (layout *PartLayout) GetNumLedsMultiPart(parts []string) int {
+	numLeds := 0
+	for _, part := range layout.ExpandParts(parts) {
+		numLeds += layout.GetNumLeds(part)
+	}
+	return numLeds
+}
+
+// WhiteBalance returns the scaling factors for red, green and blue of a part
+func (layout *PartLayout) WhiteBalance(part string) WhiteBalance {
+	balance, exists := layout.whiteBalance[part]
+	if !exists {
+		return NeutralWhiteBalance
+	}
+	return balance
+}
+
+// SetWhiteBalance changes the scaling factors for red, green and blue of a part
+func (layout *PartLayout) SetWhiteBalance(part string, balance WhiteBalance) error {
+	if !layout.HasPart(part) {
+		return errors.New("Invalid part name")
+	}
+
+	if err := validateWhiteBalance(balance); err != nil {
+		return err
+	}
+
+	layout.whiteBalance[part] = balance
+	return nil
+}
+
+// ColorOrder returns the color order of a part (like "GRB")
+func (layout *PartLayout) ColorOrder(part string) string {
+	order, exists := layout.colorOrders[part]
+	if !exists {
+		return DefaultColorOrder
+	}
+	return order
+}
+
+// SetColorOrder changes the color order of a part, e.g. "BGR" if red and blue are swapped for this part
+func (layout *PartLayout) SetColorOrder(part string, order string) error {
+	if !layout.HasPart(part) {
+		return errors.New("Invalid part name")
+	}
+
+	if _, err := parseColorOrder(order); err != nil {
+		return err
+	}
+
+	layout.colorOrders[part] = strings.ToUpper(order)
+	return nil
+}
+
+// getTotalNumLeds returns the number of leds (max LED ID + 1 or the LEDs of all outputs)
+func (layout *PartLayout) getTotalNumLeds() int {
+	numLeds := layout.maxLedID + 1
+	if len(layout.outputs) != 0 {
+		last := layout.outputs[len(layout.outputs)-1]
+		if last.offset+last.numLeds > numLeds {
+			numLeds = last.offset + last.numLeds
+		}
+	}
+	return numLeds
+}
+
+// mapLedPartPos return the real LED ID based on the part name and position inside the part
+func (layout *PartLayout) mapLedPartPos(part string, pos int) int {
+	ledIDs, exists := layout.partLedMap[part]
+	if !exists {
+		panic("invalid part name")
+	}
+	return ledIDs[pos]
+}
+
+// getRange returns an int array from `first` to `last`. It also works if `first` is bigger than `last`.
+func getRange(first int, last int) []int {
+	var length, direction int
+	if first <= last {
+		// forwards
+		length = last - first + 1
+		direction = +1
+	} else {
+		// backwards
+		length = first - last + 1
+		direction = -1
+	}
+	result := make([]int, length)
+	for i := 0; i < length; i++ {
+		result[i] = first + (direction * i)
+	}
+	return result
+}
@@ -5,99 +5,112 @@ import (
 	"image"
 	"image/color"
 	"log"
+	"reflect"
+	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
-
-	"periph.io/x/conn/v3/display"
-	"periph.io/x/conn/v3/physic"
-	"periph.io/x/conn/v3/spi"
-	"periph.io/x/conn/v3/spi/spireg"
-	"periph.io/x/devices/v3/apa102"
-	"periph.io/x/devices/v3/screen1d"
-	"periph.io/x/host/v3"
 )
 
 // LED is used to interact with the LED stripes. First, add the single parts and then run Init.
 type LED struct {
-	spi         spi.PortCloser
-	apa102      display.Drawer
-	apa102Dummy bool
-	image       *image.NRGBA
+	outputs    []*ledOutput
+	driverName string
+
+	back   *image.NRGBA // effects draw into the back buffer
+	front  *image.NRGBA // last complete frame, swapped with the back buffer on Update
+	output *image.NRGBA64
+	frame  *image.NRGBA64
+	frame8 *image.NRGBA // frame for the drivers with 8 bits per channel
+
+	frameMux sync.RWMutex
+
+	layout atomic.Pointer[PartLayout]
+
+	gamma         float64
+	ledCorrection []*colorCorrection
 
-	parts      []string
-	partLedMap map[string][]int
-	maxLedID   int
+	ledOrder []colorOrder
 
-	maxColorSum int
-	drawDummy   bool
+	power     *powerLimiter
+	drawDummy bool
 }
 
-// NewLED creates a new LED struct. After that, `AddPart` needs to be called and then `Init`.
+// ledOutput is an opened output and the LEDs of the frame that are sent to it
+type ledOutput struct {
+	name    string
+	driver  OutputDriver
+	offset  int
+	numLeds int
+}
+
+// NewLED creates a new LED struct. After that, `SetPartLayout` needs to be called and then `Init`.
 func NewLED() *LED {
 	led := &LED{}
-	led.partLedMap = make(map[string][]int)
-	led.maxLedID = -1
+	led.layout.Store(NewPartLayout())
+	led.gamma = 1
 	return led
 }
 
-// AddPart adds a part definition for the LED stripes. The LEDs from id `first` to id `last`
-// are added to the part `name`. AddPart can be called multiple times per part.
-func (led *LED) AddPart(name string, first int, last int) {
-	// add to ordered list of part names if necessary
-	if !led.HasPart(name) {
-		led.parts = append(led.parts, name)
-	}
-
-	// add to mapping
-	led.partLedMap[name] = append(led.partLedMap[name], getRange(first, last)...)
-
-	// update max led id
-	if last > led.maxLedID {
-		led.maxLedID = last
-	}
+// Init connects to the LED stripes using the drivers from the config file
+func (led *LED) Init() error {
+	return led.InitWithDriver("")
 }
 
-// Init connects to the LED stripes
-func (led *LED) Init() error {
+// InitWithDriver connects to the LED stripes. If `driverName` is set, it is used for all outputs instead of the
+// drivers from the config file.
+func (led *LED) InitWithDriver(driverName string) error {
 	// check number of LEDs
-	numLeds := led.getTotalNumLeds()
+	layout := led.Layout()
+	numLeds := layout.getTotalNumLeds()
 	if numLeds == 0 {
 		return errors.New("No LEDs defined")
 	}
 
-	// initialize periph library
-	if _, err := host.Init(); err != nil {
-		return err
+	// without outputs, all LEDs are connected to the driver from `leds.driver`
+	if len(layout.outputs) == 0 {
+		layout.AddOutput("", viper.GetString("leds.driver"), viper.Sub("leds"), numLeds)
 	}
 
-	// initialize spispi.spiConn
-	spiConn, err := spireg.Open("")
+	// initialize output drivers
+	led.driverName = driverName
+	outputs, err := led.openOutputs(layout)
 	if err != nil {
-		log.Print("Failed to find a SPI port, printing at the console:\n")
-		screenOpts := &screen1d.Opts{X: numLeds}
-		led.apa102 = screen1d.New(screenOpts)
-		led.apa102Dummy = true
-	} else {
-		// SPI config
-		led.spi = spiConn
-		led.spi.LimitSpeed(physic.Frequency(viper.GetInt("leds.spiKHz")) * physic.KiloHertz)
-
-		// initialize apa102
-		opts := apa102.DefaultOpts
-		opts.NumPixels = numLeds
-		apa102Dev, err := apa102.New(spiConn, &opts)
-		if err != nil {
+		return err
+	}
+	led.outputs = outputs
+
+	// initialize image memory: effects draw into the back buffer which is swapped with the front buffer on Update,
+	// the corrected colors (16 bits per channel) are in output and the frame for the drivers (in the color order of
+	// the parts) is in frame
+	led.back = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+	led.front = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+	led.output = image.NewNRGBA64(image.Rect(0, 0, numLeds, 1))
+	led.frame = image.NewNRGBA64(image.Rect(0, 0, numLeds, 1))
+	led.frame8 = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+	led.updateColorOrder()
+
+	// gamma correction
+	if viper.IsSet("leds.gamma") {
+		if err := led.setGamma(viper.GetFloat64("leds.gamma")); err != nil {
 			return err
 		}
-		led.apa102 = apa102Dev
-		led.apa102Dummy = false
+	} else {
+		led.updateCorrection()
 	}
 
-	// initialize image memory
-	led.image = image.NewNRGBA(led.apa102.Bounds())
+	// limit the current of the whole frame
+	milliampsPerChannel := viper.GetFloat64("leds.power.milliampsPerChannel")
+	idleMilliampsPerLed := viper.GetFloat64("leds.power.idleMilliampsPerLed")
+	budget := viper.GetFloat64("leds.power.budget")
+
+	// old config files only have a brightness cap per LED, use the same maximum current for the whole frame
+	if !viper.IsSet("leds.power.budget") && viper.IsSet("leds.brightnessCap") {
+		budget = budgetFromBrightnessCap(viper.GetFloat64("leds.brightnessCap"), numLeds, milliampsPerChannel, idleMilliampsPerLed)
+		log.Printf("WARNING: leds.brightnessCap is not supported anymore, using a power budget of %.1f A instead. Set leds.power.budget in the config file.", budget)
+	}
 
-	// set brightness cap
-	led.maxColorSum = (3 * 255) * viper.GetInt("leds.brightnessCap") / 100
+	led.power = newPowerLimiter(milliampsPerChannel, idleMilliampsPerLed, budget)
 
 	// load other config
 	led.drawDummy = viper.GetBool("leds.drawDummy")
@@ -105,65 +118,148 @@ func (led *LED) Init() error {
 	return nil
 }
 
+// Layout returns the current part layout
+func (led *LED) Layout() *PartLayout {
+	return led.layout.Load()
+}
+
+// SetPartLayout replaces the part layout. If the LEDs are already initialized, this must not happen while a frame is
+// drawn. If the total number of LEDs changes, the output driver is opened again.
+func (led *LED) SetPartLayout(layout *PartLayout) error {
+	if led.outputs == nil {
+		// not initialized yet, Init will do the rest
+		led.layout.Store(layout)
+		return nil
+	}
+
+	numLeds := layout.getTotalNumLeds()
+	if numLeds == 0 {
+		return errors.New("No LEDs defined")
+	}
+
+	oldLayout := led.Layout()
+	if len(layout.outputs) == 0 {
+		layout.AddOutput("", viper.GetString("leds.driver"), viper.Sub("leds"), numLeds)
+	}
+
+	// open the outputs again if they changed
+	if !sameOutputs(oldLayout.outputs, layout.outputs) {
+		closeOutputs(led.outputs)
+
+		outputs, err := led.openOutputs(layout)
+		if err != nil {
+			led.outputs, _ = led.openOutputs(oldLayout)
+			return err
+		}
+		led.outputs = outputs
+	}
+
+	if numLeds != oldLayout.getTotalNumLeds() {
+		led.frameMux.Lock()
+		led.back = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+		led.front = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+		led.output = image.NewNRGBA64(image.Rect(0, 0, numLeds, 1))
+		led.frame = image.NewNRGBA64(image.Rect(0, 0, numLeds, 1))
+		led.frame8 = image.NewNRGBA(image.Rect(0, 0, numLeds, 1))
+		led.layout.Store(layout)
+		led.frameMux.Unlock()
+	} else {
+		led.frameMux.Lock()
+		led.layout.Store(layout)
+		led.frameMux.Unlock()
+	}
+
+	led.updateCorrection()
+	led.updateColorOrder()
+	return nil
+}
+
 // GetParts returns the names of all parts
 func (led *LED) GetParts() []string {
-	return led.parts
+	return led.Layout().GetParts()
 }
 
 // HasPart checks if `part` is a valid part name
 func (led *LED) HasPart(part string) bool {
-	_, exists := led.partLedMap[part]
-	return exists
+	return led.Layout().HasPart(part)
 }
 
 // GetNumLeds returns the number of leds in a part
 func (led *LED) GetNumLeds(part string) int {
-	ledIDs, exists := led.partLedMap[part]
-	if !exists {
-		panic("invalid part name")
-	}
-	return len(ledIDs)
+	return led.Layout().GetNumLeds(part)
 }
 
-// GetNumLedsMultiPart returns the number of leds of all specified parts
+// GetNumLedsMultiPart returns the number of leds of all specified parts and zones
 func (led *LED) GetNumLedsMultiPart(parts []string) int {
-	numLeds := 0
-	for _, part := range parts {
-		numLeds += led.GetNumLeds(part)
-	}
-	return numLeds
+	return led.Layout().GetNumLedsMultiPart(parts)
 }
 
-// GetColor returns the color of one pixel
+// GetColor returns the color of one pixel as set by the effects (without gamma correction and white balance).
+// It reads the back buffer, so it must only be called from the goroutine that runs the effects. Other goroutines use
+// GetFrame.
 func (led *LED) GetColor(part string, pos int) (r byte, g byte, b byte) {
 	ledID := led.mapLedPartPos(part, pos)
 
-	color := led.image.NRGBAAt(ledID, 0)
+	color := led.back.NRGBAAt(ledID, 0)
 	return color.R, color.G, color.B
 }
 
+// GetFrame returns a copy of the last complete frame that was written with Update
+func (led *LED) GetFrame() *Frame {
+	led.frameMux.RLock()
+	defer led.frameMux.RUnlock()
+
+	frame := Frame{PartLayout: led.Layout(), image: image.NewNRGBA(led.front.Rect)}
+	copy(frame.image.Pix, led.front.Pix)
+	return &frame
+}
+
+// GetOutputColor returns the color of one pixel as it was sent to the LEDs on the last Update
+func (led *LED) GetOutputColor(part string, pos int) (r byte, g byte, b byte) {
+	ledID := led.mapLedPartPos(part, pos)
+
+	color := led.output.NRGBA64At(ledID, 0)
+	return to8Bit(color.R), to8Bit(color.G), to8Bit(color.B)
+}
+
+// Gamma returns the gamma value for the color correction
+func (led *LED) Gamma() float64 {
+	return led.gamma
+}
+
+// setGamma changes the gamma value for the color correction (1 disables the correction). It is only called by Init,
+// since the correction is read by every frame.
+func (led *LED) setGamma(gamma float64) error {
+	if err := validateGamma(gamma); err != nil {
+		return err
+	}
+
+	led.gamma = gamma
+	led.updateCorrection()
+	return nil
+}
+
+// WhiteBalance returns the scaling factors for red, green and blue of a part
+func (led *LED) WhiteBalance(part string) WhiteBalance {
+	return led.Layout().WhiteBalance(part)
+}
+
 // SetColor sets the color for one pixel. UpdateColors needs to be called to make the changes visible.
 // It is NOT validated it the position is valid. See SetColorMultiPart if you need this.
 func (led *LED) SetColor(part string, pos int, r byte, g byte, b byte) {
 	ledID := led.mapLedPartPos(part, pos)
 
-	// filter colors that would need to much power
-	sum := int(r) + int(g) + int(b)
-	if sum > led.maxColorSum {
-		diff := sum - led.maxColorSum
-		r -= byte(diff * int(r) / sum)
-		g -= byte(diff * int(g) / sum)
-		b -= byte(diff * int(b) / sum)
-	}
-
-	led.image.SetNRGBA(ledID, 0, color.NRGBA{R: r, G: g, B: b, A: 255})
+	led.back.SetNRGBA(ledID, 0, color.NRGBA{R: r, G: g, B: b, A: 255})
 }
 
-// SetColorMultiPart sets the color in a LED strip that may consist of multiple parts.
+// SetColorMultiPart sets the color in a LED strip that may consist of multiple parts. Zones are expanded to their
+// parts.
 // It validates that the position is in range and also supports overflows.
 // If wrap is set, positions outside the range are mapped to the correct position (wrap around).
 // If wrap is not set, invalid positions are just ignored.
 func (led *LED) SetColorMultiPart(parts []string, pos int, r byte, g byte, b byte, wrap bool) {
+	parts = led.ExpandParts(parts)
+
 	// validate that pos is in range, wrap around or abort (depending on wrap parameter)
 	numLeds := led.GetNumLedsMultiPart(parts)
 	if pos < 0 || pos >= numLeds {
@@ -203,44 +299,279 @@ func (led *LED) SetColorAllPart(part string, r byte, g byte, b byte) {
 	}
 }
 
-// Update makes color changes visible
+// PowerEstimate returns the estimated current of the last frame
+func (led *LED) PowerEstimate() PowerEstimate {
+	return led.power.getEstimate()
+}
+
+// ColorOrder returns the color order of a part (like "GRB")
+func (led *LED) ColorOrder(part string) string {
+	return led.Layout().ColorOrder(part)
+}
+
+// Update makes color changes visible. The back buffer becomes the front buffer, the new back buffer starts with the
+// same colors so that effects can continue drawing on it.
 func (led *LED) Update() error {
-	if led.apa102Dummy && !led.drawDummy {
-		return nil
+	led.frameMux.Lock()
+	led.front, led.back = led.back, led.front
+	led.frameMux.Unlock()
+
+	// only the update goroutine writes the buffers, so no lock is needed for reading the front buffer here
+	copy(led.back.Pix, led.front.Pix)
+
+	led.applyCorrection()
+	led.power.apply(led.output)
+
+	return led.drawOutputs(led.applyColorOrder())
+}
+
+// Close disconnects the output drivers
+func (led *LED) Close() error {
+	return closeOutputs(led.outputs)
+}
+
+// openOutputs opens the drivers of all outputs of the layout. If the LEDs of an output are not reachable, the output
+// is printed at the console instead.
+func (led *LED) openOutputs(layout *PartLayout) ([]*ledOutput, error) {
+	outputs := make([]*ledOutput, 0, len(layout.outputs))
+
+	for _, output := range layout.outputs {
+		if output.numLeds == 0 {
+			continue
+		}
+
+		driverName := led.driverName
+		if driverName == "" {
+			driverName = output.driver
+		}
+		if driverName == "" {
+			driverName = DriverAPA102
+		}
+
+		driver, err := newOutputDriver(driverName, output.config)
+		if err != nil {
+			closeOutputs(outputs)
+			return nil, err
+		}
+
+		err = driver.Open(output.numLeds)
+		if err != nil {
+			if driverName == DriverConsole {
+				closeOutputs(outputs)
+				return nil, err
+			}
+
+			// fall back to the console if the LEDs are not reachable
+			log.Print("Failed to open LED driver " + driverName + " for output " + output.name + " (" + err.Error() + "), printing at the console:\n")
+			driver, _ = newOutputDriver(DriverConsole, nil)
+			if err := driver.Open(output.numLeds); err != nil {
+				closeOutputs(outputs)
+				return nil, err
+			}
+		}
+
+		outputs = append(outputs, &ledOutput{name: output.name, driver: driver, offset: output.offset, numLeds: output.numLeds})
 	}
 
-	return led.apa102.Draw(led.apa102.Bounds(), led.image, image.Point{})
+	return outputs, nil
 }
 
-// getTotalNumLeds returns the number of leds (max LED ID + 1)
-func (led *LED) getTotalNumLeds() int {
-	return led.maxLedID + 1
+// drawOutputs sends the frame to all physical outputs in parallel, so that long strips do not delay the other outputs.
+// The other outputs (like the console) are drawn one after another, so that they do not write to stdout at once.
+func (led *LED) drawOutputs(frame *image.NRGBA64) error {
+	errs := make([]error, len(led.outputs))
+
+	converted := false
+	var wg sync.WaitGroup
+	var sequential []func()
+	for i, output := range led.outputs {
+		i, output := i, output
+		capabilities := output.driver.Capabilities()
+		if !capabilities.Physical && !led.drawDummy {
+			continue
+		}
+
+		var draw func()
+		if capabilities.HighDepth {
+			driver := output.driver.(HighDepthDriver)
+			subFrame := output.subFrame64(frame)
+			draw = func() { errs[i] = driver.DrawHighDepth(subFrame) }
+		} else {
+			// most drivers need 8 bits per channel
+			if !converted {
+				for ledID := 0; ledID < frame.Rect.Dx(); ledID++ {
+					pixel := frame.NRGBA64At(ledID, 0)
+					led.frame8.SetNRGBA(ledID, 0, color.NRGBA{R: to8Bit(pixel.R), G: to8Bit(pixel.G), B: to8Bit(pixel.B), A: 255})
+				}
+				converted = true
+			}
+
+			draw = func() { errs[i] = output.driver.Draw(output.subFrame(led.frame8)) }
+		}
+
+		if !capabilities.Physical {
+			sequential = append(sequential, draw)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			draw()
+		}()
+	}
+
+	for _, draw := range sequential {
+		draw()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// mapLedPartPos return the real LED ID based on the part name and position inside the part
-func (led *LED) mapLedPartPos(part string, pos int) int {
-	ledIDs, exists := led.partLedMap[part]
-	if !exists {
-		panic("invalid part name")
+// subFrame returns the LEDs of the frame that belong to the output. The returned image shares the pixels with the
+// frame and starts at 0.
+func (output *ledOutput) subFrame(frame *image.NRGBA) *image.NRGBA {
+	return &image.NRGBA{
+		Pix:    frame.Pix[output.offset*4 : (output.offset+output.numLeds)*4],
+		Stride: output.numLeds * 4,
+		Rect:   image.Rect(0, 0, output.numLeds, 1),
 	}
-	return ledIDs[pos]
 }
 
-// getRange returns an int array from `first` to `last`. It also works if `first` is bigger than `last`.
-func getRange(first int, last int) []int {
-	var length, direction int
-	if first <= last {
-		// forwards
-		length = last - first + 1
-		direction = +1
-	} else {
-		// backwards
-		length = first - last + 1
-		direction = -1
+// subFrame64 returns the LEDs of the frame with 16 bits per channel that belong to the output, like subFrame
+func (output *ledOutput) subFrame64(frame *image.NRGBA64) *image.NRGBA64 {
+	return &image.NRGBA64{
+		Pix:    frame.Pix[output.offset*8 : (output.offset+output.numLeds)*8],
+		Stride: output.numLeds * 8,
+		Rect:   image.Rect(0, 0, output.numLeds, 1),
 	}
-	result := make([]int, length)
-	for i := 0; i < length; i++ {
-		result[i] = first + (direction * i)
+}
+
+// closeOutputs closes the drivers of the outputs and returns the first error
+func closeOutputs(outputs []*ledOutput) error {
+	var result error
+	for _, output := range outputs {
+		if err := output.driver.Close(); err != nil && result == nil {
+			result = err
+		}
 	}
 	return result
 }
+
+// sameOutputs checks if the outputs of two layouts use the same drivers, driver settings and number of LEDs
+func sameOutputs(a []*layoutOutput, b []*layoutOutput) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].name != b[i].name || a[i].driver != b[i].driver || a[i].offset != b[i].offset || a[i].numLeds != b[i].numLeds {
+			return false
+		}
+
+		if !reflect.DeepEqual(driverSettings(a[i].config), driverSettings(b[i].config)) {
+			return false
+		}
+	}
+	return true
+}
+
+// driverSettings returns all settings of a driver config, which may be nil
+func driverSettings(config *viper.Viper) map[string]interface{} {
+	if config == nil {
+		return map[string]interface{}{}
+	}
+	return config.AllSettings()
+}
+
+// updateCorrection calculates the lookup tables for gamma correction and white balance of all LEDs
+func (led *LED) updateCorrection() {
+	if led.back == nil {
+		// not initialized yet, Init will do it
+		return
+	}
+
+	layout := led.Layout()
+	defaultCorrection := newColorCorrection(led.gamma, NeutralWhiteBalance)
+	ledCorrection := make([]*colorCorrection, layout.getTotalNumLeds())
+	for i := range ledCorrection {
+		ledCorrection[i] = defaultCorrection
+	}
+
+	for part, balance := range layout.whiteBalance {
+		correction := newColorCorrection(led.gamma, balance)
+		for _, ledID := range layout.partLedMap[part] {
+			ledCorrection[ledID] = correction
+		}
+	}
+
+	led.ledCorrection = ledCorrection
+}
+
+// applyCorrection writes the corrected colors of the front buffer to output
+func (led *LED) applyCorrection() {
+	ledCorrection := led.ledCorrection
+
+	for ledID, correction := range ledCorrection {
+		pixel := led.front.NRGBAAt(ledID, 0)
+		r, g, b := correction.apply(pixel.R, pixel.G, pixel.B)
+		led.output.SetNRGBA64(ledID, 0, color.NRGBA64{R: r, G: g, B: b, A: 65535})
+	}
+}
+
+// updateColorOrder calculates the channel mapping of all LEDs. If all parts use the default order, no mapping is needed.
+func (led *LED) updateColorOrder() {
+	if led.frame == nil {
+		// not initialized yet, Init will do it
+		return
+	}
+
+	layout := led.Layout()
+	var ledOrder []colorOrder
+	for part, orderStr := range layout.colorOrders {
+		order, _ := parseColorOrder(orderStr)
+		if order == identityColorOrder {
+			continue
+		}
+
+		if ledOrder == nil {
+			ledOrder = make([]colorOrder, layout.getTotalNumLeds())
+			for i := range ledOrder {
+				ledOrder[i] = identityColorOrder
+			}
+		}
+
+		for _, ledID := range layout.partLedMap[part] {
+			ledOrder[ledID] = order
+		}
+	}
+
+	led.ledOrder = ledOrder
+}
+
+// applyColorOrder returns the frame for the drivers with the channels in the color order of the parts
+func (led *LED) applyColorOrder() *image.NRGBA64 {
+	ledOrder := led.ledOrder
+	if ledOrder == nil {
+		return led.output
+	}
+
+	for ledID, order := range ledOrder {
+		color := led.output.NRGBA64At(ledID, 0)
+		color.R, color.G, color.B = order.swizzle(color.R, color.G, color.B)
+		led.frame.SetNRGBA64(ledID, 0, color)
+	}
+
+	return led.frame
+}
+
+// mapLedPartPos return the real LED ID based on the part name and position inside the part
+func (led *LED) mapLedPartPos(part string, pos int) int {
+	return led.Layout().mapLedPartPos(part, pos)
+}
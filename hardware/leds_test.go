@@ -0,0 +1,96 @@
+package hardware
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestBackBufferInvisibleUntilUpdate(t *testing.T) {
+	led := newMemoryLED(t, 3)
+
+	led.SetColor("part", 1, 10, 20, 30)
+
+	// the effects see their own changes, but not the other goroutines
+	if r, g, b := led.GetColor("part", 1); r != 10 || g != 20 || b != 30 {
+		t.Errorf("GetColor returned %d, %d, %d, expected 10, 20, 30", r, g, b)
+	}
+	if r, g, b := led.GetFrame().GetColor("part", 1); r != 0 || g != 0 || b != 0 {
+		t.Errorf("GetFrame returned %d, %d, %d before Update, expected the old color", r, g, b)
+	}
+
+	if err := led.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b := led.GetFrame().GetColor("part", 1); r != 10 || g != 20 || b != 30 {
+		t.Errorf("GetFrame returned %d, %d, %d after Update, expected 10, 20, 30", r, g, b)
+	}
+
+	// the new back buffer starts with the last frame, so that effects can continue drawing on it
+	if r, g, b := led.GetColor("part", 1); r != 10 || g != 20 || b != 30 {
+		t.Errorf("GetColor returned %d, %d, %d after Update, expected 10, 20, 30", r, g, b)
+	}
+
+	// a copy of the frame does not change with the next frames
+	frame := led.GetFrame()
+	led.SetColor("part", 1, 1, 2, 3)
+	if err := led.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b := frame.GetColor("part", 1); r != 10 || g != 20 || b != 30 {
+		t.Errorf("old frame changed to %d, %d, %d", r, g, b)
+	}
+}
+
+// TestGetFrameWhileDrawing reads frames while the effects draw and update them. Every frame has one color for all
+// LEDs, so a frame that is read while it is drawn has different colors. Shared buffers are found by the race detector.
+func TestGetFrameWhileDrawing(t *testing.T) {
+	const numLeds = 100
+	const numFrames = 200
+
+	led := newMemoryLED(t, numLeds)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				frame := led.GetFrame()
+				r0, _, _ := frame.GetColor("part", 0)
+				for pos := 1; pos < numLeds; pos++ {
+					if r, _, _ := frame.GetColor("part", pos); r != r0 {
+						t.Errorf("incomplete frame: LED 0 has %d, LED %d has %d", r0, pos, r)
+						return
+					}
+				}
+
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	for i := 0; i < numFrames; i++ {
+		// draw the frame LED by LED and let the readers run in between, so that a reader of the back buffer would see
+		// mixed colors even with one CPU
+		for pos := 0; pos < numLeds; pos++ {
+			led.SetColor("part", pos, byte(i), byte(i), byte(i))
+			if pos%10 == 0 {
+				runtime.Gosched()
+			}
+		}
+		if err := led.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+}
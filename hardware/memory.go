@@ -0,0 +1,35 @@
+package hardware
+
+import (
+	"image"
+
+	"github.com/spf13/viper"
+)
+
+// memoryDriver discards all frames. It is used if the colors are only read from memory, e.g. to render visuals.
+type memoryDriver struct{}
+
+// newMemoryDriver creates a new memory driver
+func newMemoryDriver(config *viper.Viper) OutputDriver {
+	return &memoryDriver{}
+}
+
+// Open does nothing for the memory driver
+func (driver *memoryDriver) Open(numLeds int) error {
+	return nil
+}
+
+// Draw does nothing for the memory driver
+func (driver *memoryDriver) Draw(frame *image.NRGBA) error {
+	return nil
+}
+
+// Close does nothing for the memory driver
+func (driver *memoryDriver) Close() error {
+	return nil
+}
+
+// Capabilities returns the features of the memory driver
+func (driver *memoryDriver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{Physical: false}
+}
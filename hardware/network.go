@@ -0,0 +1,249 @@
+package hardware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ProtocolArtNet sends the LED data via Art-Net
+	ProtocolArtNet = "artnet"
+
+	// ProtocolSACN sends the LED data via sACN (E1.31)
+	ProtocolSACN = "sacn"
+
+	// dmxUniverseSize is the number of channels of a DMX universe
+	dmxUniverseSize = 512
+
+	// networkSourceName is the name that is sent to the receivers
+	networkSourceName = "lightbull"
+)
+
+// NetworkTarget describes where the LEDs of a part are sent to over the network
+type NetworkTarget struct {
+	// Protocol is either "artnet" or "sacn"
+	Protocol string `mapstructure:"protocol"`
+
+	// Address is the IP address (optionally with port) of the receiver. If it is empty, Art-Net is sent as broadcast
+	// and sACN to the multicast group of the universe.
+	Address string `mapstructure:"address"`
+
+	// Universe is the first DMX universe. If a part does not fit into it, the following universes are used.
+	Universe int `mapstructure:"universe"`
+
+	// Channel is the first DMX channel (1 - 512) in the first universe, default is 1
+	Channel int `mapstructure:"channel"`
+}
+
+// NetworkOutput sends the LED data of parts to remote pixel controllers using Art-Net or sACN
+type NetworkOutput struct {
+	conn *net.UDPConn
+	cid  [16]byte
+
+	universes []*dmxUniverse
+	pixels    []networkPixel
+}
+
+// dmxUniverse is the data of one universe for one receiver
+type dmxUniverse struct {
+	protocol string
+	address  *net.UDPAddr
+	universe int
+	sequence byte
+
+	data   []byte
+	length int
+}
+
+// networkPixel maps one LED of a part to the channels of a universe
+type networkPixel struct {
+	part     string
+	pos      int
+	universe *dmxUniverse
+	channel  int
+}
+
+// NewNetworkOutput creates a new network output. After that, `AddTarget` needs to be called for the parts and then `Open`.
+func NewNetworkOutput() *NetworkOutput {
+	return &NetworkOutput{cid: uuid.New()}
+}
+
+// AddTarget maps the `numLeds` LEDs of `part` onto the universes of the target. Every LED uses three channels and
+// LEDs are never split between two universes.
+func (output *NetworkOutput) AddTarget(part string, numLeds int, target NetworkTarget) error {
+	channel := target.Channel
+	if channel == 0 {
+		channel = 1
+	}
+	if channel < 1 || channel > dmxUniverseSize {
+		return errors.New("Invalid DMX channel for part " + part)
+	}
+
+	universe := target.Universe
+	channel-- // use zero based channels internally
+
+	for pos := 0; pos < numLeds; pos++ {
+		// continue in the next universe if the LED does not fit anymore
+		if channel+3 > dmxUniverseSize {
+			universe++
+			channel = 0
+		}
+
+		dmx, err := output.getUniverse(target.Protocol, target.Address, universe)
+		if err != nil {
+			return errors.New("Invalid network target for part " + part + ": " + err.Error())
+		}
+
+		output.pixels = append(output.pixels, networkPixel{part: part, pos: pos, universe: dmx, channel: channel})
+
+		channel += 3
+		if channel > dmx.length {
+			dmx.length = channel
+		}
+	}
+
+	return nil
+}
+
+// Open creates the UDP socket. Nothing happens if no targets were added.
+func (output *NetworkOutput) Open() error {
+	if len(output.universes) == 0 {
+		return nil
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return err
+	}
+
+	output.conn = conn
+	return nil
+}
+
+// Update sends the current colors of all mapped parts (after color correction)
+func (output *NetworkOutput) Update(led *LED) error {
+	if output.conn == nil {
+		return nil
+	}
+
+	// collect colors
+	for _, pixel := range output.pixels {
+		r, g, b := led.GetOutputColor(pixel.part, pixel.pos)
+		pixel.universe.data[pixel.channel] = r
+		pixel.universe.data[pixel.channel+1] = g
+		pixel.universe.data[pixel.channel+2] = b
+	}
+
+	// send one packet per universe
+	var lastErr error
+	for _, dmx := range output.universes {
+		var packet []byte
+		if dmx.protocol == ProtocolArtNet {
+			packet = encodeArtDmx(dmx.universe, dmx.sequence, dmx.data[:dmx.length])
+		} else {
+			packet = encodeSACN(output.cid, networkSourceName, dmx.universe, dmx.sequence, dmx.data[:dmx.length])
+		}
+
+		// Art-Net uses 0 to disable sequencing
+		dmx.sequence++
+		if dmx.sequence == 0 && dmx.protocol == ProtocolArtNet {
+			dmx.sequence = 1
+		}
+
+		_, err := output.conn.WriteToUDP(packet, dmx.address)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// Close closes the UDP socket
+func (output *NetworkOutput) Close() error {
+	if output.conn == nil {
+		return nil
+	}
+
+	err := output.conn.Close()
+	output.conn = nil
+	return err
+}
+
+// getUniverse returns the universe for the receiver, it is created if necessary
+func (output *NetworkOutput) getUniverse(protocol string, address string, universe int) (*dmxUniverse, error) {
+	udpAddr, err := resolveNetworkTarget(protocol, address, universe)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dmx := range output.universes {
+		if dmx.protocol == protocol && dmx.universe == universe && dmx.address.String() == udpAddr.String() {
+			return dmx, nil
+		}
+	}
+
+	dmx := &dmxUniverse{
+		protocol: protocol,
+		address:  udpAddr,
+		universe: universe,
+		data:     make([]byte, dmxUniverseSize),
+	}
+	if protocol == ProtocolArtNet {
+		dmx.sequence = 1
+	}
+
+	output.universes = append(output.universes, dmx)
+	return dmx, nil
+}
+
+// ValidateUniverse checks that the universe can be used with the protocol (Art-Net: 0 - 32767, sACN: 1 - 63999)
+func ValidateUniverse(protocol string, universe int) error {
+	switch protocol {
+	case ProtocolArtNet:
+		if universe < 0 || universe > artNetMaxUniverse {
+			return fmt.Errorf("Art-Net universe %d out of range", universe)
+		}
+	case ProtocolSACN:
+		if universe < sacnMinUniverse || universe > sacnMaxUniverse {
+			return fmt.Errorf("sACN universe %d out of range", universe)
+		}
+	default:
+		return errors.New("Unknown protocol: " + protocol)
+	}
+	return nil
+}
+
+// resolveNetworkTarget validates the universe and returns the UDP address for the protocol
+func resolveNetworkTarget(protocol string, address string, universe int) (*net.UDPAddr, error) {
+	if err := ValidateUniverse(protocol, universe); err != nil {
+		return nil, err
+	}
+
+	var defaultIP net.IP
+	var defaultPort int
+
+	if protocol == ProtocolArtNet {
+		defaultIP = net.IPv4bcast
+		defaultPort = ArtNetPort
+	} else {
+		defaultIP = sacnMulticastAddress(universe)
+		defaultPort = SACNPort
+	}
+
+	if address == "" {
+		return &net.UDPAddr{IP: defaultIP, Port: defaultPort}, nil
+	}
+
+	// address with port
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return net.ResolveUDPAddr("udp4", address)
+	}
+
+	// only address
+	return net.ResolveUDPAddr("udp4", net.JoinHostPort(address, strconv.Itoa(defaultPort)))
+}
@@ -0,0 +1,141 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEncodeArtDmx(t *testing.T) {
+	packet := encodeArtDmx(0x1234, 7, []byte{1, 2, 3, 4, 5, 6})
+
+	if !bytes.Equal(packet[0:8], artNetID) {
+		t.Errorf("ID is % X, expected % X", packet[0:8], artNetID)
+	}
+	if universe := int(packet[14]) | int(packet[15])<<8; universe != 0x1234 {
+		t.Errorf("universe is %d, expected %d", universe, 0x1234)
+	}
+	if packet[12] != 7 {
+		t.Errorf("sequence is %d, expected 7", packet[12])
+	}
+	if !bytes.Equal(packet[artNetHeaderSize:], []byte{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("data is % X, expected 01 02 03 04 05 06", packet[artNetHeaderSize:])
+	}
+
+	// the length of ArtDmx data is always even
+	packet = encodeArtDmx(1, 1, []byte{1, 2, 3})
+	if length := binary.BigEndian.Uint16(packet[16:18]); length != 4 {
+		t.Errorf("length is %d, expected 4", length)
+	}
+	if !bytes.Equal(packet[artNetHeaderSize:], []byte{1, 2, 3, 0}) {
+		t.Errorf("data is % X, expected padding to even length", packet[artNetHeaderSize:])
+	}
+}
+
+func TestEncodeSACN(t *testing.T) {
+	data := []byte{10, 20, 30, 40, 50, 60, 70}
+	packet := encodeSACN(uuid.New(), networkSourceName, 63999, 42, data)
+
+	if !bytes.Equal(packet[4:16], sacnPacketID) {
+		t.Errorf("packet ID is % X, expected % X", packet[4:16], sacnPacketID)
+	}
+	if universe := binary.BigEndian.Uint16(packet[113:115]); universe != 63999 {
+		t.Errorf("universe is %d, expected 63999", universe)
+	}
+	if packet[111] != 42 {
+		t.Errorf("sequence is %d, expected 42", packet[111])
+	}
+	if count := binary.BigEndian.Uint16(packet[123:125]); int(count) != len(data)+1 {
+		t.Errorf("property count is %d, expected %d", count, len(data)+1)
+	}
+	if !bytes.Equal(packet[sacnHeaderSize:], data) {
+		t.Errorf("data is % X, expected % X", packet[sacnHeaderSize:], data)
+	}
+}
+
+func TestAddTargetSplitsUniverses(t *testing.T) {
+	output := NewNetworkOutput()
+
+	// channel 508 - 510 is the last LED that fits into the first universe
+	err := output.AddTarget("part", 3, NetworkTarget{Protocol: ProtocolArtNet, Address: "127.0.0.1", Universe: 1, Channel: 508})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		universe int
+		channel  int
+	}{
+		{1, 507},
+		{2, 0},
+		{2, 3},
+	}
+
+	if len(output.pixels) != len(expected) {
+		t.Fatalf("%d pixels mapped, expected %d", len(output.pixels), len(expected))
+	}
+	for i, pixel := range output.pixels {
+		if pixel.universe.universe != expected[i].universe || pixel.channel != expected[i].channel {
+			t.Errorf("LED %d is in universe %d at channel %d, expected universe %d at channel %d",
+				i, pixel.universe.universe, pixel.channel, expected[i].universe, expected[i].channel)
+		}
+	}
+
+	if len(output.universes) != 2 || output.universes[0].length != 510 || output.universes[1].length != 6 {
+		t.Errorf("unexpected universes or lengths")
+	}
+
+	if err := output.AddTarget("invalid", 1, NetworkTarget{Protocol: ProtocolSACN, Universe: 0}); err == nil {
+		t.Error("sACN universe 0 was accepted")
+	}
+}
+
+func TestNetworkOutputUpdate(t *testing.T) {
+	receiver, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer receiver.Close()
+
+	// LEDs in memory with gamma 1, so the colors are sent unchanged
+	led := newMemoryLED(t, 2)
+
+	led.SetColor("part", 0, 1, 2, 3)
+	led.SetColor("part", 1, 4, 5, 6)
+	if err := led.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := NewNetworkOutput()
+	target := NetworkTarget{Protocol: ProtocolSACN, Address: receiver.LocalAddr().String(), Universe: 5}
+	if err := output.AddTarget("part", 2, target); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	if err := output.Update(led); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([]byte, 1024)
+	receiver.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := receiver.ReadFromUDP(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := buffer[:n]
+	if universe := binary.BigEndian.Uint16(packet[113:115]); universe != 5 {
+		t.Errorf("universe is %d, expected 5", universe)
+	}
+	if !bytes.Equal(packet[sacnHeaderSize:], []byte{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("data is % X, expected 01 02 03 04 05 06", packet[sacnHeaderSize:])
+	}
+}
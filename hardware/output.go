@@ -0,0 +1,89 @@
+package hardware
+
+import (
+	"errors"
+	"image"
+
+	"github.com/spf13/viper"
+)
+
+// OutputDriver is the interface for LED strip implementations (like APA102 over SPI or the console)
+type OutputDriver interface {
+	// Open connects to the LED strip with `numLeds` LEDs
+	Open(numLeds int) error
+
+	// Draw sends one frame to the LED strip. The frame is exactly one pixel high and `numLeds` pixels wide.
+	Draw(frame *image.NRGBA) error
+
+	// Close disconnects from the LED strip
+	Close() error
+
+	// Capabilities returns the features that are supported by the driver
+	Capabilities() DriverCapabilities
+}
+
+// DriverCapabilities describes the features of an output driver
+type DriverCapabilities struct {
+	// Physical is set if real LEDs are controlled and not only a simulation
+	Physical bool
+
+	// White is set if the LEDs have a separate white channel
+	White bool
+
+	// HighDepth is set if the driver implements HighDepthDriver and gets the frames with 16 bits per channel
+	HighDepth bool
+}
+
+// HighDepthDriver is implemented by output drivers that can use more than 8 bits per channel
+type HighDepthDriver interface {
+	// DrawHighDepth sends one frame with 16 bits per channel to the LED strip. The frame is exactly one pixel high
+	// and `numLeds` pixels wide.
+	DrawHighDepth(frame *image.NRGBA64) error
+}
+
+// DriverFactory creates a new output driver. `config` is the configuration section of the output.
+type DriverFactory func(config *viper.Viper) OutputDriver
+
+const (
+	// DriverAPA102 is the driver for APA102 LED stripes connected via SPI
+	DriverAPA102 = "apa102"
+
+	// DriverWS2812 is the driver for WS2812B LED stripes connected via SPI
+	DriverWS2812 = "ws2812"
+
+	// DriverSK6812 is the driver for SK6812 RGBW LED stripes connected via SPI
+	DriverSK6812 = "sk6812"
+
+	// DriverConsole is the driver that prints the LEDs at the console
+	DriverConsole = "console"
+
+	// DriverMemory is the driver that only keeps the colors in memory
+	DriverMemory = "memory"
+)
+
+var drivers = map[string]DriverFactory{
+	DriverAPA102:  newAPA102Driver,
+	DriverWS2812:  newWS2812Driver,
+	DriverSK6812:  newSK6812Driver,
+	DriverConsole: newConsoleDriver,
+	DriverMemory:  newMemoryDriver,
+}
+
+// RegisterDriver makes a new output driver available under the given name. It can be selected with `leds.driver`.
+func RegisterDriver(name string, factory DriverFactory) {
+	drivers[name] = factory
+}
+
+// newOutputDriver creates the output driver with the given name
+func newOutputDriver(name string, config *viper.Viper) (OutputDriver, error) {
+	factory, exists := drivers[name]
+	if !exists {
+		return nil, errors.New("Unknown LED driver: " + name)
+	}
+
+	if config == nil {
+		config = viper.New()
+	}
+
+	return factory(config), nil
+}
@@ -0,0 +1,228 @@
+package hardware
+
+import (
+	"errors"
+	"strconv"
+)
+
+// PartDefinition is the name and the LED ranges (first and last LED ID) of a part. If outputs are configured, the
+// LED IDs are counted per output.
+type PartDefinition struct {
+	Name   string  `json:"name"`
+	Output string  `json:"output,omitempty"`
+	Leds   [][]int `json:"leds"`
+}
+
+// definitions returns the names and LED ranges of the parts in the config file
+func (partConfig *ledsFormat) definitions() []PartDefinition {
+	definitions := make([]PartDefinition, 0, len(partConfig.Parts))
+	for _, part := range partConfig.Parts {
+		definitions = append(definitions, PartDefinition{Name: part.Name, Output: part.Output, Leds: part.Leds})
+	}
+	return definitions
+}
+
+// outputNumLeds returns the number of LEDs of an output: either `leds` of the output or the highest LED ID of the
+// parts on this output in the config file + 1. Without outputs, it returns the number of all LEDs.
+func (partConfig *ledsFormat) outputNumLeds(name string) int {
+	if len(partConfig.Outputs) == 0 {
+		return partConfig.numLeds()
+	}
+
+	numLeds := 0
+	for _, output := range partConfig.Outputs {
+		if output.Name == name {
+			numLeds = output.Leds
+		}
+	}
+	if numLeds > 0 {
+		return numLeds
+	}
+
+	for _, part := range partConfig.Parts {
+		if part.Output != name {
+			continue
+		}
+
+		for _, leds := range part.Leds {
+			for _, id := range leds {
+				if id+1 > numLeds {
+					numLeds = id + 1
+				}
+			}
+		}
+	}
+	return numLeds
+}
+
+// hasOutput checks if an output with the given name is configured. Without outputs, only the empty name is valid.
+func (partConfig *ledsFormat) hasOutput(name string) bool {
+	if len(partConfig.Outputs) == 0 {
+		return name == ""
+	}
+
+	for _, output := range partConfig.Outputs {
+		if output.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// numLeds returns the number of LEDs that are connected: either `leds.count` or the highest LED ID of the parts in the
+// config file + 1. If outputs are configured, it is the sum of the LEDs of all outputs.
+func (partConfig *ledsFormat) numLeds() int {
+	if len(partConfig.Outputs) != 0 {
+		numLeds := 0
+		for _, output := range partConfig.Outputs {
+			numLeds += partConfig.outputNumLeds(output.Name)
+		}
+		return numLeds
+	}
+
+	if partConfig.Count > 0 {
+		return partConfig.Count
+	}
+
+	numLeds := 0
+	for _, part := range partConfig.Parts {
+		for _, leds := range part.Leds {
+			for _, id := range leds {
+				if id+1 > numLeds {
+					numLeds = id + 1
+				}
+			}
+		}
+	}
+	return numLeds
+}
+
+// validatePartDefinitions checks that the part names are unique, that all LED IDs exist on the output of the part and
+// that no LED belongs to more than one part
+func validatePartDefinitions(definitions []PartDefinition, partConfig *ledsFormat) error {
+	if len(definitions) == 0 {
+		return errors.New("At least one part is needed")
+	}
+
+	type ledKey struct {
+		output string
+		id     int
+	}
+
+	names := make(map[string]bool)
+	used := make(map[ledKey]string)
+
+	for _, part := range definitions {
+		if part.Name == "" {
+			return errors.New("Part name is missing")
+		}
+		if names[part.Name] {
+			return errors.New("Duplicate part name " + part.Name)
+		}
+		names[part.Name] = true
+
+		if len(part.Leds) == 0 {
+			return errors.New("Part " + part.Name + " has no LEDs")
+		}
+
+		if len(partConfig.Outputs) != 0 && part.Output == "" {
+			return errors.New("Missing output for part " + part.Name)
+		}
+		if !partConfig.hasOutput(part.Output) {
+			return errors.New("Unknown output " + part.Output + " for part " + part.Name)
+		}
+		numLeds := partConfig.outputNumLeds(part.Output)
+
+		for _, leds := range part.Leds {
+			if len(leds) != 2 {
+				return errors.New("LED ranges of part " + part.Name + " need a first and a last LED ID")
+			}
+
+			for _, id := range leds {
+				if id < 0 || id >= numLeds {
+					return errors.New("LED " + strconv.Itoa(id) + " of part " + part.Name + " does not exist, there are only " + strconv.Itoa(numLeds) + " LEDs")
+				}
+			}
+
+			for _, id := range getRange(leds[0], leds[1]) {
+				key := ledKey{output: part.Output, id: id}
+				if other, exists := used[key]; exists {
+					return errors.New("LED " + strconv.Itoa(id) + " of part " + part.Name + " is already used by part " + other)
+				}
+				used[key] = part.Name
+			}
+		}
+	}
+
+	return nil
+}
+
+// NumLeds returns the number of LEDs that can be used by the parts
+func (hw *Hardware) NumLeds() int {
+	hw.partsMux.Lock()
+	defer hw.partsMux.Unlock()
+
+	return hw.partConfig.numLeds()
+}
+
+// PartDefinitions returns the names and LED ranges of the current parts
+func (hw *Hardware) PartDefinitions() []PartDefinition {
+	layout := hw.Led.Layout()
+
+	definitions := make([]PartDefinition, 0, len(layout.GetParts()))
+	for _, part := range layout.GetParts() {
+		definition := PartDefinition{Name: part, Output: layout.GetOutput(part)}
+		for _, leds := range layout.GetRanges(part) {
+			definition.Leds = append(definition.Leds, []int{leds[0], leds[1]})
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// LockParts needs to be held while the part definitions are read and changed with SetPartDefinitions, so that they
+// are not changed in between (e.g. by ReloadParts)
+func (hw *Hardware) LockParts() {
+	hw.partsMux.Lock()
+}
+
+// UnlockParts releases the lock for the part definitions
+func (hw *Hardware) UnlockParts() {
+	hw.partsMux.Unlock()
+}
+
+// SetPartDefinitions replaces the names and LED ranges of the parts from the config file and uses them from the next
+// frame on. The other settings of the parts (like color order or positions) are still taken from the config file if
+// a part with the same name exists there. `save` is called when the definitions are valid, before they are used (e.g.
+// to store them). If it fails, nothing is changed. `apply` is called between two frames right after the new layout was
+// set, e.g. to update the groups that use renamed parts. LockParts needs to be held.
+func (hw *Hardware) SetPartDefinitions(definitions []PartDefinition, save func() error, apply func(layout *PartLayout)) error {
+	partConfig := hw.partConfig
+	if err := validatePartDefinitions(definitions, partConfig); err != nil {
+		return err
+	}
+
+	layout, err := loadPartLayout(partConfig, definitions)
+	if err != nil {
+		return err
+	}
+
+	network, err := newNetworkOutputFromConfig(partConfig, layout)
+	if err != nil {
+		return err
+	}
+
+	if save != nil {
+		if err := save(); err != nil {
+			network.Close()
+			return err
+		}
+	}
+
+	if err := hw.applyPartLayout(layout, network, apply); err != nil {
+		return err
+	}
+
+	hw.definitions = definitions
+	return nil
+}
@@ -0,0 +1,182 @@
+package hardware
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+)
+
+// Player plays a recording back through the LEDs
+type Player struct {
+	recording *recordingFile
+
+	path string
+	loop bool
+
+	position    int64  // nanoseconds since start of recording
+	current     []byte // frame that is shown
+	currentTime int64
+	next        []byte // frame that is shown after nextTime
+	nextTime    int64
+	nextValid   bool
+
+	mux sync.Mutex
+}
+
+// NewPlayer creates a new player
+func NewPlayer() *Player {
+	return &Player{}
+}
+
+// Start opens the recording and starts the playback. If `loop` is set, the recording is repeated.
+func (player *Player) Start(path string, loop bool) error {
+	recording, err := openRecording(path)
+	if err != nil {
+		return err
+	}
+
+	player.mux.Lock()
+	defer player.mux.Unlock()
+
+	if player.recording != nil {
+		player.recording.close()
+	}
+
+	player.recording = recording
+	player.path = path
+	player.loop = loop
+	player.rewind()
+
+	return nil
+}
+
+// Stop ends the playback
+func (player *Player) Stop() error {
+	player.mux.Lock()
+	defer player.mux.Unlock()
+
+	if player.recording == nil {
+		return errors.New("No playback running")
+	}
+
+	player.stop()
+	return nil
+}
+
+// Playing returns whether a recording is played and the path of the file
+func (player *Player) Playing() (bool, string) {
+	player.mux.Lock()
+	defer player.mux.Unlock()
+
+	return player.recording != nil, player.path
+}
+
+// Update advances the playback by `nanoseconds` and sets the colors of the LEDs. Parts of the recording that do not
+// exist anymore are ignored.
+func (player *Player) Update(led *LED, nanoseconds int64) {
+	player.mux.Lock()
+	defer player.mux.Unlock()
+
+	if player.recording == nil {
+		return
+	}
+
+	player.position += nanoseconds
+
+	// skip to the last frame that should be visible now
+	for player.nextValid && player.nextTime <= player.position {
+		player.current, player.next = player.next, player.current
+		player.currentTime = player.nextTime
+		player.readNext()
+
+		// end of recording: start again or stop
+		if !player.nextValid && player.recording != nil && player.loop {
+			// the last frame is at the end of the recording. without any length, looping would never leave this loop.
+			length := player.currentTime
+			if length <= 0 {
+				log.Print("Recording is too short to loop, stopping after the last frame")
+				player.loop = false
+				break
+			}
+
+			if err := player.reopen(); err != nil {
+				log.Print("Failed to restart playback: " + err.Error())
+				player.stop()
+				return
+			}
+
+			// keep the time that passed since the end, so that the loop continues with the right frame
+			player.position %= length
+		}
+	}
+
+	if player.recording == nil {
+		return
+	}
+
+	// show the current frame
+	pos := 0
+	for _, part := range player.recording.parts {
+		if led.HasPart(part.Name) {
+			numLeds := led.GetNumLeds(part.Name)
+			for i := 0; i < part.NumLeds && i < numLeds; i++ {
+				led.SetColor(part.Name, i, player.current[pos+3*i], player.current[pos+3*i+1], player.current[pos+3*i+2])
+			}
+		}
+		pos += 3 * part.NumLeds
+	}
+
+	// stop after the last frame if not looping
+	if !player.nextValid && !player.loop {
+		player.stop()
+	}
+}
+
+// rewind resets the position and reads the first frame
+func (player *Player) rewind() {
+	player.position = 0
+	player.currentTime = 0
+	player.current = make([]byte, player.recording.frameSize)
+	player.next = make([]byte, player.recording.frameSize)
+	player.readNext()
+}
+
+// reopen starts reading the recording from the beginning, the position is not changed
+func (player *Player) reopen() error {
+	player.recording.close()
+
+	recording, err := openRecording(player.path)
+	if err != nil {
+		player.recording = nil
+		return err
+	}
+
+	player.recording = recording
+	player.readNext()
+	return nil
+}
+
+// readNext reads the next frame of the recording
+func (player *Player) readNext() {
+	timestamp, err := player.recording.readFrame(player.next)
+	if err != nil {
+		if err != io.EOF {
+			log.Print("Failed to read recording: " + err.Error())
+		}
+		player.nextValid = false
+		return
+	}
+
+	player.nextTime = int64(timestamp) * 1000000
+	player.nextValid = true
+}
+
+// stop closes the recording
+func (player *Player) stop() {
+	if player.recording != nil {
+		player.recording.close()
+	}
+	player.recording = nil
+	player.path = ""
+}
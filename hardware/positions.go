@@ -0,0 +1,134 @@
+package hardware
+
+import (
+	"errors"
+	"math"
+)
+
+// Position is the physical position of a LED. The unit does not matter, but it should be the same for all parts.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// NewPosition creates a position from a list with two (x, y) or three (x, y, z) coordinates
+func NewPosition(coordinates []float64) (Position, error) {
+	switch len(coordinates) {
+	case 2:
+		return Position{X: coordinates[0], Y: coordinates[1]}, nil
+	case 3:
+		return Position{X: coordinates[0], Y: coordinates[1], Z: coordinates[2]}, nil
+	default:
+		return Position{}, errors.New("Positions need two or three coordinates")
+	}
+}
+
+// InterpolateSegment returns `count` positions that are evenly distributed on the line from `start` to `end`
+// (including both points)
+func InterpolateSegment(start Position, end Position, count int) []Position {
+	positions := make([]Position, count)
+
+	for i := 0; i < count; i++ {
+		factor := 0.0
+		if count > 1 {
+			factor = float64(i) / float64(count-1)
+		}
+
+		positions[i] = Position{
+			X: start.X + (end.X-start.X)*factor,
+			Y: start.Y + (end.Y-start.Y)*factor,
+			Z: start.Z + (end.Z-start.Z)*factor,
+		}
+	}
+
+	return positions
+}
+
+// Distance returns the euclidean distance between two positions
+func (position Position) Distance(other Position) float64 {
+	dx := position.X - other.X
+	dy := position.Y - other.Y
+	dz := position.Z - other.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// HasPositions checks if positions were configured for the part
+func (layout *PartLayout) HasPositions(part string) bool {
+	_, exists := layout.partPositions[part]
+	return exists
+}
+
+// SetPositions sets the positions of all LEDs of a part
+func (layout *PartLayout) SetPositions(part string, positions []Position) error {
+	if !layout.HasPart(part) {
+		return errors.New("Invalid part name")
+	}
+
+	if len(positions) != layout.GetNumLeds(part) {
+		return errors.New("Number of positions does not match the number of LEDs")
+	}
+
+	layout.partPositions[part] = positions
+	return nil
+}
+
+// GetPosition returns the position of a LED. If no positions are configured for the part, the position in the part
+// is used as X coordinate.
+func (layout *PartLayout) GetPosition(part string, pos int) Position {
+	positions, exists := layout.partPositions[part]
+	if !exists {
+		return Position{X: float64(pos)}
+	}
+	return positions[pos]
+}
+
+// HasPositions checks if positions were configured for the part
+func (led *LED) HasPositions(part string) bool {
+	return led.Layout().HasPositions(part)
+}
+
+// GetPosition returns the position of a LED. If no positions are configured for the part, the position in the part
+// is used as X coordinate.
+func (led *LED) GetPosition(part string, pos int) Position {
+	return led.Layout().GetPosition(part, pos)
+}
+
+// GetPositionMultiPart returns the position of a LED in a LED strip that may consist of multiple parts and zones.
+// The position needs to be in range.
+func (led *LED) GetPositionMultiPart(parts []string, pos int) Position {
+	for _, part := range led.ExpandParts(parts) {
+		if pos < led.GetNumLeds(part) {
+			return led.GetPosition(part, pos)
+		}
+
+		pos -= led.GetNumLeds(part)
+	}
+
+	panic("position out of range")
+}
+
+// GetBounds returns the smallest and biggest coordinates of all LEDs of the parts and zones
+func (led *LED) GetBounds(parts []string) (min Position, max Position) {
+	min = Position{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	max = Position{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+
+	for _, part := range led.ExpandParts(parts) {
+		for i := 0; i < led.GetNumLeds(part); i++ {
+			position := led.GetPosition(part, i)
+			min.X = math.Min(min.X, position.X)
+			min.Y = math.Min(min.Y, position.Y)
+			min.Z = math.Min(min.Z, position.Z)
+			max.X = math.Max(max.X, position.X)
+			max.Y = math.Max(max.Y, position.Y)
+			max.Z = math.Max(max.Z, position.Z)
+		}
+	}
+
+	// no LEDs
+	if min.X > max.X {
+		return Position{}, Position{}
+	}
+
+	return min, max
+}
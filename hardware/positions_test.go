@@ -0,0 +1,196 @@
+package hardware
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadTestLayout reads the LED section of a config file and creates the part layout
+func loadTestLayout(t *testing.T, leds string) (*PartLayout, error) {
+	config := viper.New()
+	config.SetConfigType("yaml")
+	if err := config.ReadConfig(strings.NewReader("leds:\n" + leds)); err != nil {
+		t.Fatal(err)
+	}
+
+	partConfig, err := readPartConfig(config.Sub("leds"))
+	if err != nil {
+		return nil, err
+	}
+	return loadPartLayout(partConfig, nil)
+}
+
+func TestInterpolateSegment(t *testing.T) {
+	start := Position{X: 0, Y: 10, Z: -2}
+	end := Position{X: 4, Y: 2, Z: 2}
+
+	tests := []struct {
+		count    int
+		expected []Position
+	}{
+		{0, []Position{}},
+		// a single LED is at the start
+		{1, []Position{start}},
+		// both end points are included
+		{2, []Position{start, end}},
+		{5, []Position{start, {X: 1, Y: 8, Z: -1}, {X: 2, Y: 6, Z: 0}, {X: 3, Y: 4, Z: 1}, end}},
+	}
+
+	for _, test := range tests {
+		positions := InterpolateSegment(start, end, test.count)
+		if len(positions) != len(test.expected) {
+			t.Errorf("InterpolateSegment with %d LEDs returned %d positions", test.count, len(positions))
+			continue
+		}
+		for i := range positions {
+			if positions[i] != test.expected[i] {
+				t.Errorf("InterpolateSegment with %d LEDs: position %d is %v, expected %v", test.count, i, positions[i], test.expected[i])
+			}
+		}
+	}
+}
+
+func TestPositionsFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		part     string
+		expected []Position
+		valid    bool
+	}{
+		{
+			name: "explicit list",
+			part: "      leds: [[0, 2]]\n" +
+				"      positions: [[0, 0], [1, 2], [3, 4, 5]]\n",
+			expected: []Position{{X: 0, Y: 0}, {X: 1, Y: 2}, {X: 3, Y: 4, Z: 5}},
+			valid:    true,
+		},
+		{
+			name: "single segment for the whole part",
+			part: "      leds: [[0, 2]]\n" +
+				"      segments:\n" +
+				"        - start: [0, 0]\n" +
+				"          end: [0, 10]\n",
+			expected: []Position{{X: 0, Y: 0}, {X: 0, Y: 5}, {X: 0, Y: 10}},
+			valid:    true,
+		},
+		{
+			name: "explicit list and segments",
+			part: "      leds: [[0, 3]]\n" +
+				"      positions: [[5, 5]]\n" +
+				"      segments:\n" +
+				"        - start: [0, 0]\n" +
+				"          end: [2, 0]\n" +
+				"          leds: 3\n",
+			expected: []Position{{X: 5, Y: 5}, {X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+			valid:    true,
+		},
+		{
+			name:  "no positions",
+			part:  "      leds: [[0, 2]]\n",
+			valid: true,
+		},
+		{
+			name: "too few positions",
+			part: "      leds: [[0, 2]]\n" +
+				"      positions: [[0, 0], [1, 1]]\n",
+		},
+		{
+			name: "too many LEDs in segments",
+			part: "      leds: [[0, 2]]\n" +
+				"      segments:\n" +
+				"        - start: [0, 0]\n" +
+				"          end: [1, 0]\n" +
+				"          leds: 2\n" +
+				"        - start: [1, 0]\n" +
+				"          end: [2, 0]\n" +
+				"          leds: 2\n",
+		},
+		{
+			name: "malformed position",
+			part: "      leds: [[0, 2]]\n" +
+				"      positions: [[0], [1, 1], [2, 2]]\n",
+		},
+		{
+			name: "malformed segment",
+			part: "      leds: [[0, 2]]\n" +
+				"      segments:\n" +
+				"        - start: [0, 0, 0, 0]\n" +
+				"          end: [1, 0]\n",
+		},
+	}
+
+	for _, test := range tests {
+		layout, err := loadTestLayout(t, "  driver: memory\n  parts:\n    - name: part\n"+test.part)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: invalid positions were accepted", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if layout.HasPositions("part") != (test.expected != nil) {
+			t.Errorf("%s: HasPositions is %t", test.name, layout.HasPositions("part"))
+		}
+
+		for i := 0; i < layout.GetNumLeds("part"); i++ {
+			expected := Position{X: float64(i)}
+			if test.expected != nil {
+				expected = test.expected[i]
+			}
+
+			if position := layout.GetPosition("part", i); position != expected {
+				t.Errorf("%s: position of LED %d is %v, expected %v", test.name, i, position, expected)
+			}
+		}
+	}
+}
+
+func TestPositionsMultiPart(t *testing.T) {
+	layout, err := loadTestLayout(t, "  driver: memory\n"+
+		"  parts:\n"+
+		"    - name: a\n"+
+		"      leds: [[0, 1]]\n"+
+		"      positions: [[-1, 2, 0], [3, -4, 1]]\n"+
+		"    - name: b\n"+
+		"      leds: [[2, 4]]\n"+
+		"      segments:\n"+
+		"        - start: [0, 0]\n"+
+		"          end: [0, 10]\n"+
+		"  zones:\n"+
+		"    - name: all\n"+
+		"      parts: [a, b]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	led := newMemoryLEDWithLayout(t, layout)
+
+	// the zone is expanded to its parts, the positions of "b" start after the LEDs of "a"
+	expected := []Position{{X: -1, Y: 2}, {X: 3, Y: -4, Z: 1}, {X: 0, Y: 0}, {X: 0, Y: 5}, {X: 0, Y: 10}}
+	for i, position := range expected {
+		if actual := led.GetPositionMultiPart([]string{"all"}, i); actual != position {
+			t.Errorf("position of LED %d is %v, expected %v", i, actual, position)
+		}
+	}
+
+	min, max := led.GetBounds([]string{"all"})
+	if min != (Position{X: -1, Y: -4, Z: 0}) || max != (Position{X: 3, Y: 10, Z: 1}) {
+		t.Errorf("bounds are %v - %v, expected {-1 -4 0} - {3 10 1}", min, max)
+	}
+
+	min, max = led.GetBounds([]string{"b"})
+	if min != (Position{}) || max != (Position{Y: 10}) {
+		t.Errorf("bounds of b are %v - %v, expected {0 0 0} - {0 10 0}", min, max)
+	}
+
+	// no LEDs
+	min, max = led.GetBounds(nil)
+	if min != (Position{}) || max != (Position{}) {
+		t.Errorf("bounds without LEDs are %v - %v, expected zero", min, max)
+	}
+}
@@ -0,0 +1,90 @@
+package hardware
+
+import (
+	"image"
+	"sync"
+)
+
+// PowerEstimate contains the estimated current of the LEDs
+type PowerEstimate struct {
+	// Current is the estimated current in amps for the last frame before it was limited
+	Current float64 `json:"current"`
+
+	// Budget is the maximum current in amps (0 means unlimited)
+	Budget float64 `json:"budget"`
+
+	// Scale is the factor that was applied to the last frame to stay within the budget (1 means no limitation)
+	Scale float64 `json:"scale"`
+}
+
+// powerLimiter estimates the current of a frame and scales it down if the power budget is exceeded
+type powerLimiter struct {
+	milliampsPerChannel float64
+	idleMilliampsPerLed float64
+	budgetMilliamps     float64
+
+	estimate PowerEstimate
+	mux      sync.Mutex
+}
+
+// newPowerLimiter creates a new power limiter. `budget` is in amps, 0 disables the limitation.
+func newPowerLimiter(milliampsPerChannel float64, idleMilliampsPerLed float64, budget float64) *powerLimiter {
+	return &powerLimiter{
+		milliampsPerChannel: milliampsPerChannel,
+		idleMilliampsPerLed: idleMilliampsPerLed,
+		budgetMilliamps:     budget * 1000,
+		estimate:            PowerEstimate{Budget: budget, Scale: 1},
+	}
+}
+
+// budgetFromBrightnessCap returns the budget in amps that matches the old brightness cap (in percent of full
+// brightness per LED): the current of all LEDs at the highest brightness that the cap allowed.
+func budgetFromBrightnessCap(brightnessCap float64, numLeds int, milliampsPerChannel float64, idleMilliampsPerLed float64) float64 {
+	perLed := idleMilliampsPerLed + 3*milliampsPerChannel*brightnessCap/100
+	return perLed * float64(numLeds) / 1000
+}
+
+// apply estimates the current of the frame and scales all colors down if necessary
+func (limiter *powerLimiter) apply(frame *image.NRGBA64) {
+	numLeds := frame.Bounds().Dx()
+
+	// sum of all channels, every channel needs the configured current at full brightness
+	channelSum := 0
+	for i := 0; i < numLeds; i++ {
+		color := frame.NRGBA64At(i, 0)
+		channelSum += int(color.R) + int(color.G) + int(color.B)
+	}
+
+	idle := limiter.idleMilliampsPerLed * float64(numLeds)
+	active := float64(channelSum) * limiter.milliampsPerChannel / 65535
+
+	// scale the whole frame so that the budget is not exceeded. the idle current cannot be reduced.
+	scale := 1.0
+	if limiter.budgetMilliamps > 0 && idle+active > limiter.budgetMilliamps && active > 0 {
+		scale = (limiter.budgetMilliamps - idle) / active
+		if scale < 0 {
+			scale = 0
+		}
+
+		for i := 0; i < numLeds; i++ {
+			color := frame.NRGBA64At(i, 0)
+			color.R = uint16(float64(color.R) * scale)
+			color.G = uint16(float64(color.G) * scale)
+			color.B = uint16(float64(color.B) * scale)
+			frame.SetNRGBA64(i, 0, color)
+		}
+	}
+
+	limiter.mux.Lock()
+	limiter.estimate.Current = (idle + active) / 1000
+	limiter.estimate.Scale = scale
+	limiter.mux.Unlock()
+}
+
+// getEstimate returns the estimate of the last frame
+func (limiter *powerLimiter) getEstimate() PowerEstimate {
+	limiter.mux.Lock()
+	defer limiter.mux.Unlock()
+
+	return limiter.estimate
+}
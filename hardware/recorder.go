@@ -0,0 +1,149 @@
+package hardware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordingTempExtension is appended to the path of a recording while it is written
+const recordingTempExtension = ".tmp"
+
+// Recorder writes all frames that are sent to the LEDs into a file
+type Recorder struct {
+	file   *os.File
+	gzip   *gzip.Writer
+	writer *bufio.Writer
+
+	path  string
+	parts []RecordingPart
+	start time.Time
+	data  []byte
+
+	mux sync.Mutex
+}
+
+// NewRecorder creates a new recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start creates the file and starts the recording of the frames. The part layout is taken from `led`. The frames are
+// written to a temporary file that replaces the file at `path` on Stop, so that a recording with the same name can
+// still be played until then.
+func (recorder *Recorder) Start(path string, led *LED) error {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	if recorder.file != nil {
+		return errors.New("Recording already running")
+	}
+
+	// part layout
+	parts := make([]RecordingPart, 0, len(led.GetParts()))
+	frameSize := 0
+	for _, part := range led.GetParts() {
+		parts = append(parts, RecordingPart{Name: part, NumLeds: led.GetNumLeds(part)})
+		frameSize += 3 * led.GetNumLeds(part)
+	}
+
+	// create file and write header
+	file, err := os.Create(path + recordingTempExtension)
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(file)
+	writer := bufio.NewWriter(gzipWriter)
+
+	if err := writeRecordingHeader(writer, parts); err != nil {
+		gzipWriter.Close()
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	recorder.file = file
+	recorder.gzip = gzipWriter
+	recorder.writer = writer
+	recorder.path = path
+	recorder.parts = parts
+	recorder.start = time.Now()
+	recorder.data = make([]byte, 4+frameSize)
+
+	return nil
+}
+
+// Stop finishes the recording, closes the file and moves it to the path from Start
+func (recorder *Recorder) Stop() error {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	if recorder.file == nil {
+		return errors.New("No recording running")
+	}
+
+	err := recorder.writer.Flush()
+	if closeErr := recorder.gzip.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := recorder.file.Close(); err == nil {
+		err = closeErr
+	}
+	if renameErr := os.Rename(recorder.file.Name(), recorder.path); err == nil {
+		err = renameErr
+	}
+
+	recorder.file = nil
+	recorder.gzip = nil
+	recorder.writer = nil
+	recorder.path = ""
+
+	return err
+}
+
+// Recording returns whether a recording is running and the path of the file
+func (recorder *Recorder) Recording() (bool, string) {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	return recorder.file != nil, recorder.path
+}
+
+// WriteFrame appends the current colors of the LEDs to the recording. Nothing happens if no recording is running.
+func (recorder *Recorder) WriteFrame(led *LED) error {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	if recorder.file == nil {
+		return nil
+	}
+
+	binary.BigEndian.PutUint32(recorder.data, uint32(time.Since(recorder.start).Milliseconds()))
+
+	// the layout is fixed for the whole recording, parts that were removed or shortened are black
+	layout := led.Layout()
+	pos := 4
+	for _, part := range recorder.parts {
+		numLeds := 0
+		if layout.HasPart(part.Name) {
+			numLeds = layout.GetNumLeds(part.Name)
+		}
+
+		for i := 0; i < part.NumLeds; i++ {
+			if i < numLeds {
+				recorder.data[pos], recorder.data[pos+1], recorder.data[pos+2] = led.GetColor(part.Name, i)
+			} else {
+				recorder.data[pos], recorder.data[pos+1], recorder.data[pos+2] = 0, 0, 0
+			}
+			pos += 3
+		}
+	}
+
+	_, err := recorder.writer.Write(recorder.data)
+	return err
+}
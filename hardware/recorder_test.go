@@ -0,0 +1,69 @@
+package hardware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// record writes a recording with one frame in the given color
+func record(t *testing.T, recorder *Recorder, path string, led *LED, r byte, g byte, b byte) {
+	if err := recorder.Start(path, led); err != nil {
+		t.Fatal(err)
+	}
+
+	led.SetColorAll(r, g, b)
+	if err := recorder.WriteFrame(led); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordWhilePlaying(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lbrec")
+	led := newMemoryLED(t, 3)
+	recorder := NewRecorder()
+
+	record(t, recorder, path, led, 1, 2, 3)
+
+	player := NewPlayer()
+	if err := player.Start(path, true); err != nil {
+		t.Fatal(err)
+	}
+	defer player.Stop()
+
+	// a new recording with the same name must not change the file that is played
+	if err := recorder.Start(path, led); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := openRecording(path)
+	if err != nil {
+		t.Fatalf("played file was changed by the new recording: %v", err)
+	}
+	recording.close()
+
+	led.SetColorAll(4, 5, 6)
+	if err := recorder.WriteFrame(led); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// now the new recording replaces the old one
+	if _, err := os.Stat(path + recordingTempExtension); !os.IsNotExist(err) {
+		t.Error("temporary file still exists")
+	}
+
+	if err := player.Start(path, false); err != nil {
+		t.Fatal(err)
+	}
+	player.Update(led, 0)
+	if r, g, b := led.GetColor("part", 0); r != 4 || g != 5 || b != 6 {
+		t.Errorf("played color is %d, %d, %d, expected the new recording", r, g, b)
+	}
+}
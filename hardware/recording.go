@@ -0,0 +1,149 @@
+package hardware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// Recordings are gzip compressed files with this content:
+//
+//	header: "LBREC", version (uint8), number of parts (uint16), per part: name length (uint8), name, number of LEDs (uint16)
+//	frames: milliseconds since start (uint32), RGB of all LEDs of all parts in the order of the header
+//
+// All numbers are big endian.
+
+const (
+	// recordingMagic is at the beginning of every recording
+	recordingMagic = "LBREC"
+
+	// recordingVersion is the version of the file format
+	recordingVersion = 1
+)
+
+// RecordingPart is a part in the layout of a recording
+type RecordingPart struct {
+	Name    string `json:"name"`
+	NumLeds int    `json:"numLeds"`
+}
+
+// writeRecordingHeader writes the magic, version and part layout
+func writeRecordingHeader(w io.Writer, parts []RecordingPart) error {
+	header := []byte(recordingMagic)
+	header = append(header, recordingVersion)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(parts)))
+
+	for _, part := range parts {
+		if len(part.Name) > 255 {
+			return errors.New("Part name too long for recording: " + part.Name)
+		}
+
+		header = append(header, byte(len(part.Name)))
+		header = append(header, part.Name...)
+		header = binary.BigEndian.AppendUint16(header, uint16(part.NumLeds))
+	}
+
+	_, err := w.Write(header)
+	return err
+}
+
+// readRecordingHeader reads magic, version and part layout
+func readRecordingHeader(r io.Reader) ([]RecordingPart, error) {
+	header := make([]byte, len(recordingMagic)+3)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("Not a lightbull recording")
+	}
+
+	if header[len(recordingMagic)] != recordingVersion {
+		return nil, errors.New("Unsupported version of recording")
+	}
+
+	numParts := int(binary.BigEndian.Uint16(header[len(recordingMagic)+1:]))
+	parts := make([]RecordingPart, numParts)
+
+	for i := range parts {
+		nameLength := make([]byte, 1)
+		if _, err := io.ReadFull(r, nameLength); err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, int(nameLength[0])+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		parts[i].Name = string(data[:nameLength[0]])
+		parts[i].NumLeds = int(binary.BigEndian.Uint16(data[nameLength[0]:]))
+	}
+
+	return parts, nil
+}
+
+// recordingFile is an opened recording for reading
+type recordingFile struct {
+	file   *os.File
+	gzip   *gzip.Reader
+	reader *bufio.Reader
+
+	parts     []RecordingPart
+	frameSize int
+}
+
+// openRecording opens a recording and reads the header
+func openRecording(path string) (*recordingFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	recording := &recordingFile{file: file, gzip: gzipReader, reader: bufio.NewReader(gzipReader)}
+
+	recording.parts, err = readRecordingHeader(recording.reader)
+	if err != nil {
+		recording.close()
+		return nil, err
+	}
+
+	for _, part := range recording.parts {
+		recording.frameSize += 3 * part.NumLeds
+	}
+
+	return recording, nil
+}
+
+// readFrame reads the next frame into `data` (which needs to have frameSize bytes) and returns the timestamp in ms.
+// At the end of the recording, io.EOF is returned.
+func (recording *recordingFile) readFrame(data []byte) (uint32, error) {
+	timestamp := make([]byte, 4)
+	if _, err := io.ReadFull(recording.reader, timestamp); err != nil {
+		return 0, err
+	}
+
+	if _, err := io.ReadFull(recording.reader, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint32(timestamp), nil
+}
+
+// close closes the recording
+func (recording *recordingFile) close() {
+	recording.gzip.Close()
+	recording.file.Close()
+}
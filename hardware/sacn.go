@@ -0,0 +1,100 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// SACNPort is the UDP port for sACN (E1.31)
+	SACNPort = 5568
+
+	// sacnHeaderSize is the size of the root, framing and DMP layer in front of the DMX data (including start code)
+	sacnHeaderSize = 126
+
+	// sacnVectorRootData, sacnVectorFramingData and sacnVectorDMPSetProperty are the PDU vectors of a data packet
+	sacnVectorRootData       = 0x00000004
+	sacnVectorFramingData    = 0x00000002
+	sacnVectorDMPSetProperty = 0x02
+
+	// sacnDefaultPriority is the priority of the sent data
+	sacnDefaultPriority = 100
+
+	// sacnMinUniverse and sacnMaxUniverse are the valid universes
+	sacnMinUniverse = 1
+	sacnMaxUniverse = 63999
+)
+
+var sacnPacketID = []byte{'A', 'S', 'C', '-', 'E', '1', '.', '1', '7', 0, 0, 0}
+
+// encodeSACN builds an E1.31 data packet for `universe` with the DMX `data` (at most 512 bytes)
+func encodeSACN(cid [16]byte, sourceName string, universe int, sequence byte, data []byte) []byte {
+	packet := make([]byte, sacnHeaderSize+len(data))
+
+	// root layer
+	binary.BigEndian.PutUint16(packet[0:2], 0x0010) // preamble size
+	binary.BigEndian.PutUint16(packet[2:4], 0x0000) // postamble size
+	copy(packet[4:16], sacnPacketID)
+	binary.BigEndian.PutUint16(packet[16:18], sacnFlagsAndLength(len(packet)-16))
+	binary.BigEndian.PutUint32(packet[18:22], sacnVectorRootData)
+	copy(packet[22:38], cid[:])
+
+	// framing layer
+	binary.BigEndian.PutUint16(packet[38:40], sacnFlagsAndLength(len(packet)-38))
+	binary.BigEndian.PutUint32(packet[40:44], sacnVectorFramingData)
+	copy(packet[44:107], sourceName) // 64 bytes, null terminated
+	packet[108] = sacnDefaultPriority
+	binary.BigEndian.PutUint16(packet[109:111], 0) // synchronization address
+	packet[111] = sequence
+	packet[112] = 0 // options
+	binary.BigEndian.PutUint16(packet[113:115], uint16(universe))
+
+	// DMP layer
+	binary.BigEndian.PutUint16(packet[115:117], sacnFlagsAndLength(len(packet)-115))
+	packet[117] = sacnVectorDMPSetProperty
+	packet[118] = 0xa1                             // address type and data type
+	binary.BigEndian.PutUint16(packet[119:121], 0) // first property address
+	binary.BigEndian.PutUint16(packet[121:123], 1) // address increment
+	binary.BigEndian.PutUint16(packet[123:125], uint16(len(data)+1))
+	packet[125] = 0 // DMX start code
+	copy(packet[sacnHeaderSize:], data)
+
+	return packet
+}
+
+// decodeSACN parses an E1.31 data packet and returns the universe and the DMX data
+func decodeSACN(packet []byte) (int, []byte, error) {
+	if len(packet) < sacnHeaderSize || !bytes.Equal(packet[4:16], sacnPacketID) {
+		return 0, nil, errors.New("Not a sACN packet")
+	}
+
+	if binary.BigEndian.Uint32(packet[18:22]) != sacnVectorRootData || binary.BigEndian.Uint32(packet[40:44]) != sacnVectorFramingData {
+		return 0, nil, errors.New("Not a sACN data packet")
+	}
+
+	// only DMX data with the null start code is supported
+	if packet[117] != sacnVectorDMPSetProperty || packet[125] != 0 {
+		return 0, nil, errors.New("Unsupported sACN data")
+	}
+
+	universe := int(binary.BigEndian.Uint16(packet[113:115]))
+	length := int(binary.BigEndian.Uint16(packet[123:125])) - 1
+	if length < 0 || length > dmxUniverseSize || sacnHeaderSize+length > len(packet) {
+		return 0, nil, errors.New("Invalid length of sACN packet")
+	}
+
+	return universe, packet[sacnHeaderSize : sacnHeaderSize+length], nil
+}
+
+// sacnFlagsAndLength returns the flags and length field of a PDU
+func sacnFlagsAndLength(length int) uint16 {
+	return 0x7000 | uint16(length&0x0fff)
+}
+
+// sacnMulticastAddress returns the multicast group of an universe
+func sacnMulticastAddress(universe int) net.IP {
+	return net.ParseIP(fmt.Sprintf("239.255.%d.%d", universe>>8, universe&0xff))
+}
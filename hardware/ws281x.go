@@ -0,0 +1,184 @@
+package hardware
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/spf13/viper"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+const (
+	// ws281xSpiFrequency is the SPI clock: three SPI bits per data bit result in the 800 kHz data rate of WS281x LEDs
+	ws281xSpiFrequency = 2400 * physic.KiloHertz
+
+	// ws281xResetBytes is the number of zero bytes sent after a frame, 80µs at 2.4 MHz (SK6812 need 80µs to latch)
+	ws281xResetBytes = 24
+
+	// ws281xBit0 and ws281xBit1 are the SPI bit patterns for a data bit: 0 is short high, 1 is long high
+	ws281xBit0 = 0x4 // 100
+	ws281xBit1 = 0x6 // 110
+)
+
+// ws281xDriver sends the frames to WS2812B (RGB) or SK6812 (RGBW) LED stripes. The data line is connected to MOSI
+// and the timing is generated by encoding every data bit as three SPI bits.
+type ws281xDriver struct {
+	spi  spi.PortCloser
+	conn spi.Conn
+
+	spiPort string
+	rgbw    bool
+
+	buffer []byte
+}
+
+// newWS2812Driver creates a new driver for WS2812B stripes. It uses `spiPort` (default: first port) from the config.
+func newWS2812Driver(config *viper.Viper) OutputDriver {
+	return &ws281xDriver{
+		spiPort: config.GetString("spiPort"),
+		rgbw:    false,
+	}
+}
+
+// newSK6812Driver creates a new driver for SK6812 RGBW stripes. It uses `spiPort` (default: first port) from the config.
+func newSK6812Driver(config *viper.Viper) OutputDriver {
+	return &ws281xDriver{
+		spiPort: config.GetString("spiPort"),
+		rgbw:    true,
+	}
+}
+
+// Open initializes the SPI port
+func (driver *ws281xDriver) Open(numLeds int) error {
+	// initialize periph library
+	if _, err := host.Init(); err != nil {
+		return err
+	}
+
+	// initialize SPI
+	spiConn, err := spireg.Open(driver.spiPort)
+	if err != nil {
+		return err
+	}
+
+	dataConn, err := spiConn.Connect(ws281xSpiFrequency, spi.Mode0, 8)
+	if err != nil {
+		spiConn.Close()
+		return err
+	}
+
+	// the frame needs to be sent in one transfer, otherwise the pause between the transfers latches the data
+	driver.buffer = make([]byte, ws281xFrameSize(numLeds, driver.rgbw))
+	if limits, ok := dataConn.(conn.Limits); ok && limits.MaxTxSize() > 0 && limits.MaxTxSize() < len(driver.buffer) {
+		spiConn.Close()
+		return fmt.Errorf("SPI transfer size too small: need %d bytes but the maximum is %d (increase spidev.bufsiz)", len(driver.buffer), limits.MaxTxSize())
+	}
+
+	driver.spi = spiConn
+	driver.conn = dataConn
+	return nil
+}
+
+// Draw encodes the frame and sends it to the LEDs
+func (driver *ws281xDriver) Draw(frame *image.NRGBA) error {
+	if driver.conn == nil {
+		return errors.New("LED driver is not open")
+	}
+
+	encodeWS281x(driver.buffer, frame, driver.rgbw)
+	return driver.conn.Tx(driver.buffer, nil)
+}
+
+// Close closes the SPI port
+func (driver *ws281xDriver) Close() error {
+	if driver.spi == nil {
+		return nil
+	}
+
+	err := driver.spi.Close()
+	driver.spi = nil
+	driver.conn = nil
+	return err
+}
+
+// Capabilities returns the features of the WS281x driver
+func (driver *ws281xDriver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{Physical: true, White: driver.rgbw}
+}
+
+// ws281xFrameSize returns the number of SPI bytes for `numLeds` LEDs, including the reset time
+func ws281xFrameSize(numLeds int, rgbw bool) int {
+	channels := 3
+	if rgbw {
+		channels = 4
+	}
+
+	// every data bit needs three SPI bits, so every channel byte needs three SPI bytes
+	return numLeds*channels*3 + ws281xResetBytes
+}
+
+// encodeWS281x writes the SPI bit pattern for the frame into `dst` which needs to have the size returned by
+// ws281xFrameSize. The channels are sent in GRB (or GRBW) order. In RGBW mode, the white channel is derived from the
+// common part of red, green and blue.
+func encodeWS281x(dst []byte, frame *image.NRGBA, rgbw bool) {
+	numLeds := frame.Bounds().Dx()
+	pos := 0
+
+	for i := 0; i < numLeds; i++ {
+		color := frame.NRGBAAt(frame.Bounds().Min.X+i, frame.Bounds().Min.Y)
+
+		if rgbw {
+			r, g, b, w := rgbToRGBW(color.R, color.G, color.B)
+			pos += encodeWS281xByte(dst[pos:], g)
+			pos += encodeWS281xByte(dst[pos:], r)
+			pos += encodeWS281xByte(dst[pos:], b)
+			pos += encodeWS281xByte(dst[pos:], w)
+		} else {
+			pos += encodeWS281xByte(dst[pos:], color.G)
+			pos += encodeWS281xByte(dst[pos:], color.R)
+			pos += encodeWS281xByte(dst[pos:], color.B)
+		}
+	}
+
+	// reset: keep the data line low
+	for ; pos < len(dst); pos++ {
+		dst[pos] = 0
+	}
+}
+
+// encodeWS281xByte writes the 24 SPI bits for one data byte (MSB first) into `dst` and returns the number of bytes
+func encodeWS281xByte(dst []byte, value byte) int {
+	var bits uint32
+	for i := 7; i >= 0; i-- {
+		bits <<= 3
+		if value&(1<<i) != 0 {
+			bits |= ws281xBit1
+		} else {
+			bits |= ws281xBit0
+		}
+	}
+
+	dst[0] = byte(bits >> 16)
+	dst[1] = byte(bits >> 8)
+	dst[2] = byte(bits)
+	return 3
+}
+
+// rgbToRGBW moves the common part of red, green and blue to the white channel
+func rgbToRGBW(r byte, g byte, b byte) (byte, byte, byte, byte) {
+	w := r
+	if g < w {
+		w = g
+	}
+	if b < w {
+		w = b
+	}
+
+	return r - w, g - w, b - w, w
+}
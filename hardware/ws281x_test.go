@@ -0,0 +1,98 @@
+package hardware
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestEncodeWS281xByte(t *testing.T) {
+	tests := []struct {
+		value    byte
+		expected []byte
+	}{
+		{0x00, []byte{0x92, 0x49, 0x24}},
+		{0xFF, []byte{0xDB, 0x6D, 0xB6}},
+		{0x80, []byte{0xD2, 0x49, 0x24}},
+		{0x01, []byte{0x92, 0x49, 0x26}},
+	}
+
+	for _, test := range tests {
+		dst := make([]byte, 3)
+		if n := encodeWS281xByte(dst, test.value); n != 3 {
+			t.Errorf("encodeWS281xByte(0x%02X) returned %d bytes, expected 3", test.value, n)
+		}
+		if !bytes.Equal(dst, test.expected) {
+			t.Errorf("encodeWS281xByte(0x%02X) = % X, expected % X", test.value, dst, test.expected)
+		}
+	}
+}
+
+func TestEncodeWS281xOrder(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	frame.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, G: 0x00, B: 0x80, A: 255})
+
+	dst := make([]byte, ws281xFrameSize(1, false))
+	encodeWS281x(dst, frame, false)
+
+	// green, red, blue
+	expected := []byte{0x92, 0x49, 0x24, 0xDB, 0x6D, 0xB6, 0xD2, 0x49, 0x24}
+	if !bytes.Equal(dst[:9], expected) {
+		t.Errorf("encodeWS281x = % X, expected GRB % X", dst[:9], expected)
+	}
+}
+
+func TestEncodeWS281xRGBW(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	frame.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, G: 0x80, B: 0x80, A: 255})
+
+	dst := make([]byte, ws281xFrameSize(1, true))
+	encodeWS281x(dst, frame, true)
+
+	// white is the common part (0x80), green and blue are 0 and red is 0x7F
+	expected := []byte{0x92, 0x49, 0x24, 0x9B, 0x6D, 0xB6, 0x92, 0x49, 0x24, 0xD2, 0x49, 0x24}
+	if !bytes.Equal(dst[:12], expected) {
+		t.Errorf("encodeWS281x = % X, expected GRBW % X", dst[:12], expected)
+	}
+}
+
+func TestRGBToRGBW(t *testing.T) {
+	tests := []struct {
+		r, g, b    byte
+		r2, g2, b2 byte
+		w          byte
+	}{
+		{255, 255, 255, 0, 0, 0, 255},
+		{255, 0, 0, 255, 0, 0, 0},
+		{200, 100, 50, 150, 50, 0, 50},
+		{0, 0, 0, 0, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		r, g, b, w := rgbToRGBW(test.r, test.g, test.b)
+		if r != test.r2 || g != test.g2 || b != test.b2 || w != test.w {
+			t.Errorf("rgbToRGBW(%d, %d, %d) = %d, %d, %d, %d, expected %d, %d, %d, %d",
+				test.r, test.g, test.b, r, g, b, w, test.r2, test.g2, test.b2, test.w)
+		}
+	}
+}
+
+func TestEncodeWS281xReset(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	frame.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 255})
+	frame.SetNRGBA(1, 0, color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 255})
+
+	// old data in the buffer must be overwritten by the reset
+	dst := bytes.Repeat([]byte{0xAA}, ws281xFrameSize(2, false))
+	encodeWS281x(dst, frame, false)
+
+	if len(dst) != 2*3*3+ws281xResetBytes {
+		t.Fatalf("frame size is %d, expected %d", len(dst), 2*3*3+ws281xResetBytes)
+	}
+	for i, value := range dst[2*3*3:] {
+		if value != 0 {
+			t.Fatalf("reset byte %d is 0x%02X, expected 0", i, value)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package hardware
+
+import "errors"
+
+// AddZone adds a zone, which is a name for an ordered list of parts. Zones can be used instead of parts and behave
+// like one continuous strip in the MultiPart functions.
+func (layout *PartLayout) AddZone(name string, parts []string) error {
+	if name == "" {
+		return errors.New("Zone name is missing")
+	}
+	if layout.HasPart(name) || layout.HasZone(name) {
+		return errors.New("Name is already used")
+	}
+	if len(parts) == 0 {
+		return errors.New("Zone has no parts")
+	}
+
+	for _, part := range parts {
+		if !layout.HasPart(part) {
+			return errors.New("Unknown part " + part)
+		}
+	}
+
+	layout.zones = append(layout.zones, name)
+	layout.zoneParts[name] = parts
+	return nil
+}
+
+// GetZones returns the names of all zones
+func (layout *PartLayout) GetZones() []string {
+	return layout.zones
+}
+
+// HasZone checks if `zone` is a valid zone name
+func (layout *PartLayout) HasZone(zone string) bool {
+	_, exists := layout.zoneParts[zone]
+	return exists
+}
+
+// GetZoneParts returns the parts of a zone
+func (layout *PartLayout) GetZoneParts(zone string) []string {
+	parts, exists := layout.zoneParts[zone]
+	if !exists {
+		panic("invalid zone name")
+	}
+	return parts
+}
+
+// ExpandParts replaces the zones in `parts` by their parts. If there are no zones in the list, it is returned as is.
+func (layout *PartLayout) ExpandParts(parts []string) []string {
+	hasZone := false
+	for _, part := range parts {
+		if layout.HasZone(part) {
+			hasZone = true
+			break
+		}
+	}
+	if !hasZone {
+		return parts
+	}
+
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if layout.HasZone(part) {
+			result = append(result, layout.zoneParts[part]...)
+		} else {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// GetZones returns the names of all zones
+func (led *LED) GetZones() []string {
+	return led.Layout().GetZones()
+}
+
+// HasZone checks if `zone` is a valid zone name
+func (led *LED) HasZone(zone string) bool {
+	return led.Layout().HasZone(zone)
+}
+
+// ExpandParts replaces the zones in `parts` by their parts
+func (led *LED) ExpandParts(parts []string) []string {
+	return led.Layout().ExpandParts(parts)
+}
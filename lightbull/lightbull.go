@@ -1,16 +1,26 @@
 package lightbull
 
 import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/light-bull/lightbull/api"
+	"github.com/light-bull/lightbull/dmx"
 	"github.com/light-bull/lightbull/events"
 	"github.com/light-bull/lightbull/hardware"
 	"github.com/light-bull/lightbull/persistence"
 	"github.com/light-bull/lightbull/shows"
+	"github.com/light-bull/lightbull/timing"
 	"github.com/spf13/viper"
 )
 
+// outputErrorLogInterval is the minimum time between two log messages about failed outputs
+const outputErrorLogInterval = 10 * time.Second
+
 // Lightbull contains all software components (`hardware`, `api`, `shows`).
 // This basically is the glue code between the other packages.
 type Lightbull struct {
@@ -19,11 +29,23 @@ type Lightbull struct {
 	API         *api.API
 	EventHub    *events.EventHub
 	Persistence *persistence.Persistence
+	DMX         *dmx.Input
+	Timer       *timing.FrameTimer
 }
 
 // New prepares the whole lightbull controller for use: it initializes the hardware, starts the
 // hardware update loop and starts the REST API.
 func New() (*Lightbull, error) {
+	return newLightbull(hardware.New)
+}
+
+// NewSimulated prepares the whole lightbull controller like New, but the LEDs are not connected
+func NewSimulated() (*Lightbull, error) {
+	return newLightbull(hardware.NewSimulated)
+}
+
+// newLightbull prepares the controller with hardware from `newHardware`
+func newLightbull(newHardware func() (*hardware.Hardware, error)) (*Lightbull, error) {
 	lightbull := Lightbull{}
 	var err error
 
@@ -37,20 +59,41 @@ func New() (*Lightbull, error) {
 	}
 
 	// initialize hardware and run update loop
-	lightbull.Hardware, err = hardware.New()
+	lightbull.Hardware, err = newHardware()
 	if err != nil {
 		return nil, err
 	}
 
+	// the parts from the part editor replace the ones from the config file
+	if lightbull.Persistence.HasConfig("parts") {
+		var parts []hardware.PartDefinition
+		err := lightbull.Persistence.LoadConfig("parts", &parts)
+		if err == nil {
+			lightbull.Hardware.LockParts()
+			err = lightbull.Hardware.SetPartDefinitions(parts, nil, nil)
+			lightbull.Hardware.UnlockParts()
+		}
+		if err != nil {
+			log.Print("Error while loading parts, using the config file: " + err.Error())
+		}
+	}
+
 	// create show collection and load shows
 	lightbull.Shows = shows.NewShowCollection()
 	lightbull.Persistence.LoadShows(lightbull.Shows)
 
+	// map DMX input onto parameters
+	lightbull.DMX = dmx.NewInput(lightbull.Hardware, lightbull.Shows, lightbull.EventHub, lightbull.Persistence)
+
 	// run update loop for modes and hardware
+	lightbull.Timer = timing.NewFrameTimer(viper.GetFloat64("leds.fps"))
 	go lightbull.UpdateLoop()
 
+	// reload parts on SIGHUP
+	go lightbull.reloadOnSignal()
+
 	// run api server
-	lightbull.API, err = api.New(lightbull.Hardware, lightbull.Shows, lightbull.EventHub, lightbull.Persistence)
+	lightbull.API, err = api.New(lightbull.Hardware, lightbull.Shows, lightbull.EventHub, lightbull.Persistence, lightbull.DMX, lightbull.Timer)
 	if err != nil {
 		return nil, err
 	}
@@ -58,22 +101,86 @@ func New() (*Lightbull, error) {
 	return &lightbull, nil
 }
 
-// UpdateLoop runs the current mode program and writes changes to the hardware in regular intervals
+// UpdateLoop runs the current mode program and writes changes to the hardware in regular intervals. The frames are
+// scheduled by a ticker, so the frame rate does not drift. If a frame takes too long, the next frames are skipped.
 func (lightbull *Lightbull) UpdateLoop() {
-	lastUpdate := time.Now()
-	sleepTime := time.Duration(1000000000.0 / viper.GetFloat64("leds.fps"))
-	for {
-		time.Sleep(sleepTime)
+	interval := lightbull.Timer.Interval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastTick := time.Now()
+	lastStart := lastTick
 
-		nanoseconds := time.Since(lastUpdate).Nanoseconds()
-		lastUpdate = time.Now()
+	var lastErrorLog time.Time
+	failedFrames := 0
+	for tick := range ticker.C {
+		start := time.Now()
+
+		// the effects get the time between the scheduled frames, so the animation is not affected by jitter
+		nanoseconds := tick.Sub(lastTick).Nanoseconds()
+		if dropped := timing.DroppedFrames(tick.Sub(lastTick), interval); dropped > 0 {
+			lightbull.Timer.AddDropped(dropped)
+		}
+		lastTick = tick
+
+		// render frame, the part layout must not change until the frame was written
+		var failures []*shows.EffectFailure
+		lightbull.Hardware.Lock()
 		show := lightbull.Shows.CurrentShow()
-		if show != nil {
-			show.Update(lightbull.Hardware, nanoseconds)
+		if playing, _ := lightbull.Hardware.Player.Playing(); playing {
+			lightbull.Hardware.Player.Update(lightbull.Hardware.Led, nanoseconds)
+		} else if show != nil {
+			failures = show.Update(lightbull.Hardware, nanoseconds)
 		} else {
 			lightbull.Hardware.Led.SetColorAll(0, 0, 0)
 		}
+		rendered := time.Now()
+
+		// write to hardware
+		outputErr := lightbull.Hardware.Update()
+		lightbull.Hardware.Unlock()
 
-		lightbull.Hardware.Update()
+		// a broken output fails in every frame, so the errors are only logged from time to time
+		if outputErr != nil {
+			failedFrames++
+			if time.Since(lastErrorLog) >= outputErrorLogInterval {
+				log.Printf("Failed to write %d frame(s) to the outputs: %s", failedFrames, outputErr)
+				lastErrorLog = time.Now()
+				failedFrames = 0
+			}
+		}
+
+		// the event hub may block on slow clients, so the hardware must not be locked while publishing
+		for _, failure := range failures {
+			lightbull.EventHub.PublishNew(events.EffectFailed, failure, nil, uuid.Nil)
+		}
+
+		lightbull.Timer.AddFrame(start.Sub(lastStart), rendered.Sub(start), time.Since(rendered))
+		lastStart = start
+	}
+}
+
+// ReloadParts reads the LED parts from the config file again and applies them if they are valid for all shows
+func (lightbull *Lightbull) ReloadParts() error {
+	err := lightbull.Hardware.ReloadParts(lightbull.Shows.CheckParts)
+	if err != nil {
+		return err
+	}
+
+	lightbull.EventHub.PublishNew(events.PartsChanged, nil, nil, uuid.Nil)
+	return nil
+}
+
+// reloadOnSignal reloads the LED parts whenever SIGHUP is received
+func (lightbull *Lightbull) reloadOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	for range sigs {
+		if err := lightbull.ReloadParts(); err != nil {
+			log.Print("Failed to reload parts: " + err.Error())
+		} else {
+			log.Print("Reloaded parts")
+		}
 	}
 }
@@ -8,16 +8,21 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/light-bull/lightbull/events"
 	"github.com/light-bull/lightbull/shows"
 	"github.com/spf13/viper"
 )
 
+// recordingExtension is the file extension of frame recordings
+const recordingExtension = ".lbrec"
+
 // Persistence stores dynamic configuration and shows on disk
 type Persistence struct {
-	configDir string
-	showsDir  string
+	configDir     string
+	showsDir      string
+	recordingsDir string
 
 	eventhub    *events.EventHub
 	eventclient *EventClient
@@ -37,6 +42,11 @@ func NewPersistence(eventhub *events.EventHub) (*Persistence, error) {
 		return nil, errors.New("Cannot create configuration directory for shows: " + err.Error())
 	}
 
+	persistence.recordingsDir = path.Join(persistence.configDir, "recordings")
+	if err := os.MkdirAll(persistence.recordingsDir, 0755); err != nil {
+		return nil, errors.New("Cannot create directory for recordings: " + err.Error())
+	}
+
 	persistence.eventhub = eventhub
 	persistence.eventclient = newEventClient(&persistence)
 	persistence.eventhub.RegisterClient(persistence.eventclient)
@@ -100,6 +110,31 @@ func (persistence *Persistence) LoadShows(showCollection *shows.ShowCollection)
 	return
 }
 
+// RecordingPath returns the path of the file for the recording with the given name
+func (persistence *Persistence) RecordingPath(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", errors.New("Invalid name for recording")
+	}
+	return path.Join(persistence.recordingsDir, name+recordingExtension), nil
+}
+
+// RecordingName returns the name of the recording that is stored in the given file
+func (persistence *Persistence) RecordingName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), recordingExtension)
+}
+
+// Recordings returns the names of all stored recordings
+func (persistence *Persistence) Recordings() []string {
+	files, _ := filepath.Glob(persistence.recordingsDir + "/*" + recordingExtension)
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, persistence.RecordingName(file))
+	}
+
+	return names
+}
+
 // save stores the serialized object as JSON on disk
 func (persistence *Persistence) save(file string, data interface{}, secret bool) error {
 	// serialize
@@ -7,6 +7,19 @@ import (
 	"github.com/light-bull/lightbull/shows/parameters"
 )
 
+// Blink is the blink effect
+const Blink = "blink"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Blink,
+		Name:        "Blink",
+		Description: "Switches between two colors",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewBlinkEffect() },
+	})
+}
+
 // BlinkEffect is a effect that lets the LEDs blink in one color
 type BlinkEffect struct {
 	colorPrimary   *parameters.Parameter
@@ -6,6 +6,19 @@ import (
 	"image/color"
 )
 
+// Calibration is the calibration effect
+const Calibration = "calibration"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Calibration,
+		Name:        "Calibration",
+		Description: "Turns on a single LED to find out its ID",
+		Category:    CategoryTools,
+		New:         func() Effect { return NewCalibrationEffect() },
+	})
+}
+
 // CalibrationEffect is an effect that sets a single LED to a color for calibration purposes
 type CalibrationEffect struct {
 	color *parameters.Parameter
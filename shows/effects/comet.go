@@ -0,0 +1,186 @@
+package effects
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// Comet is the comet effect
+const Comet = "comet"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Comet,
+		Name:        "Comet",
+		Description: "Moving comets with a fading tail",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewCometEffect() },
+	})
+}
+
+// CometEffect is a effect that draws moving comets with a tail that gets darker
+type CometEffect struct {
+	color       *parameters.Parameter
+	speed       *parameters.Parameter
+	headSize    *parameters.Parameter
+	tailLength  *parameters.Parameter
+	decay       *parameters.Parameter
+	count       *parameters.Parameter
+	randomDecay *parameters.Parameter
+	reversed    *parameters.Parameter
+
+	currentPosition float64
+
+	// brightness factor of each LED in the tail of every comet for random decay, it is chosen when the head of the comet
+	// passes the LED
+	tailFactors  [][]float64
+	randomSource *rand.Rand
+}
+
+// NewCometEffect returns a new comet effect
+func NewCometEffect() *CometEffect {
+	comet := CometEffect{}
+
+	comet.color = parameters.NewParameter("color", parameters.Color, "Color")
+	comet.speed = parameters.NewParameter("speed", parameters.Percent, "Speed")
+	comet.headSize = parameters.NewParameter("headSize", parameters.IntegerGreaterOrEqualZero, "Head size")
+	comet.tailLength = parameters.NewParameter("tailLength", parameters.IntegerGreaterOrEqualZero, "Tail length")
+	comet.decay = parameters.NewParameter("decay", parameters.Percent, "Decay")
+	comet.count = parameters.NewParameter("count", parameters.IntegerGreaterOrEqualZero, "Number of comets")
+	comet.randomDecay = parameters.NewParameter("randomDecay", parameters.Boolean, "Random decay")
+	comet.reversed = parameters.NewParameter("reversed", parameters.Boolean, "Reversed")
+
+	setDefault(comet.color, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	setDefault(comet.speed, 30)
+	setDefault(comet.headSize, 1)
+	setDefault(comet.tailLength, 10)
+	setDefault(comet.decay, 50)
+	setDefault(comet.count, 1)
+
+	comet.randomSource = newRandomSource()
+
+	return &comet
+}
+
+// Type returns "comet"
+func (e *CometEffect) Type() string {
+	return Comet
+}
+
+// Name returns "Comet"
+func (e *CometEffect) Name() string {
+	return "Comet"
+}
+
+// Seed resets the random source with the given seed and the comets to their start position
+func (e *CometEffect) Seed(seed int64) {
+	e.randomSource.Seed(seed)
+	e.currentPosition = 0
+	e.tailFactors = nil
+}
+
+// Update decides about the changes that are caused by the effect for a certain timestep.
+func (e *CometEffect) Update(hw *hardware.Hardware, parts []string, nanoseconds int64) {
+	cometColor := e.color.Get().(color.NRGBA)
+	speed := e.speed.Get().(int)
+	headSize := e.headSize.Get().(int)
+	tailLength := e.tailLength.Get().(int)
+	decay := e.decay.Get().(int)
+	count := e.count.Get().(int)
+	randomDecay := e.randomDecay.Get().(bool)
+	reversed := e.reversed.Get().(bool)
+
+	numLeds := hw.Led.GetNumLedsMultiPart(parts)
+	if numLeds == 0 {
+		return
+	}
+	if !e.hasTailFactors(numLeds, count) {
+		e.tailFactors = make([][]float64, count)
+		for comet := range e.tailFactors {
+			e.tailFactors[comet] = make([]float64, numLeds)
+			for i := range e.tailFactors[comet] {
+				e.tailFactors[comet][i] = 1
+			}
+		}
+	}
+
+	ledsPerSecond := mapPercent(0.0, 150.0, speed)
+	getNextPosition(&e.currentPosition, ledsPerSecond, numLeds, nanoseconds, reversed)
+
+	directionFactor := float64(getDirectionFactor(reversed))
+
+	// the tail gets darker with this exponent, higher decay means a shorter visible tail
+	exponent := mapPercent(0.5, 5.0, decay)
+
+	for i := 0; i < numLeds; i++ {
+		brightness := 0.0
+
+		for comet := 0; comet < count; comet++ {
+			// the comets are evenly spaced over all LEDs
+			head := e.currentPosition + float64(comet)*float64(numLeds)/float64(count)
+
+			// distance of the LED behind the head, the float position makes the movement smooth at low speeds
+			distance := moduloFloat64(directionFactor*(head-float64(i)), float64(numLeds))
+			if distance > float64(numLeds)-1 {
+				// the LED in front of the head is partly lit
+				distance -= float64(numLeds)
+			}
+
+			var cometBrightness float64
+			switch {
+			case distance < 0:
+				cometBrightness = 1 + distance
+
+				// the head reaches this LED, so it gets a new random brightness for the tail of this comet
+				if randomDecay {
+					e.tailFactors[comet][i] = 0.2 + 0.8*e.randomSource.Float64()
+				}
+			case distance < float64(headSize):
+				cometBrightness = 1
+			case distance < float64(headSize+tailLength):
+				cometBrightness = math.Pow(1-(distance-float64(headSize))/float64(tailLength), exponent)
+
+				if randomDecay {
+					cometBrightness *= e.tailFactors[comet][i]
+				}
+			}
+
+			brightness = math.Max(brightness, cometBrightness)
+		}
+
+		mixed := getPaletteColor([]color.NRGBA{{R: 0, G: 0, B: 0}, cometColor}, brightness)
+		hw.Led.SetColorMultiPart(parts, i, mixed.R, mixed.G, mixed.B, false)
+	}
+}
+
+// hasTailFactors checks if there is a tail factor for every LED and every comet
+func (e *CometEffect) hasTailFactors(numLeds int, count int) bool {
+	if len(e.tailFactors) != count {
+		return false
+	}
+
+	for _, factors := range e.tailFactors {
+		if len(factors) != numLeds {
+			return false
+		}
+	}
+	return true
+}
+
+// Parameters returns the list of parameters
+func (e *CometEffect) Parameters() []*parameters.Parameter {
+	data := make([]*parameters.Parameter, 8)
+	data[0] = e.color
+	data[1] = e.speed
+	data[2] = e.headSize
+	data[3] = e.tailLength
+	data[4] = e.decay
+	data[5] = e.count
+	data[6] = e.randomDecay
+	data[7] = e.reversed
+	return data
+}
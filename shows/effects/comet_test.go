@@ -0,0 +1,116 @@
+package effects
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/light-bull/lightbull/hardware"
+)
+
+// litLeds returns the positions of all LEDs of the concatenated parts that are not off
+func litLeds(hw *hardware.Hardware, parts []string) []int {
+	var lit []int
+	colors := getColors(hw, parts)
+	for pos := 0; pos < len(colors)/3; pos++ {
+		if colors[3*pos] != 0 || colors[3*pos+1] != 0 || colors[3*pos+2] != 0 {
+			lit = append(lit, pos)
+		}
+	}
+	return lit
+}
+
+func TestCometMovement(t *testing.T) {
+	parts := []string{"a", "b"}
+
+	tests := []struct {
+		reversed    bool
+		nanoseconds int64
+		tailLength  int
+		expected    []int
+	}{
+		// 150 LEDs per second
+		{false, 100000000, 0, []int{15}},
+		// the comet moves from part "a" to part "b"
+		{false, 200000000, 0, []int{30}},
+		// after 50 LEDs, the comet starts again at the beginning
+		{false, 400000000, 0, []int{10}},
+		// the tail is behind the comet and wraps around the end
+		{false, 400000000, 12, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 48, 49}},
+		// reversed comets start at the end
+		{true, 100000000, 0, []int{35}},
+		{true, 100000000, 3, []int{35, 36, 37, 38}},
+	}
+
+	for _, test := range tests {
+		hw := newTestHardware(t)
+
+		comet := NewCometEffect()
+		comet.speed.Set(100)
+		comet.tailLength.Set(test.tailLength)
+		comet.decay.Set(0)
+		comet.reversed.Set(test.reversed)
+		comet.Seed(1)
+
+		comet.Update(hw, parts, test.nanoseconds)
+
+		lit := litLeds(hw, parts)
+		if len(lit) != len(test.expected) {
+			t.Errorf("reversed: %t, %d ns, tail %d: lit LEDs are %v, expected %v", test.reversed, test.nanoseconds, test.tailLength, lit, test.expected)
+			continue
+		}
+		for i := range lit {
+			if lit[i] != test.expected[i] {
+				t.Errorf("reversed: %t, %d ns, tail %d: lit LEDs are %v, expected %v", test.reversed, test.nanoseconds, test.tailLength, lit, test.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestCometTailDecay(t *testing.T) {
+	hw := newTestHardware(t)
+
+	comet := NewCometEffect()
+	comet.color.Set(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	comet.speed.Set(100)
+	comet.tailLength.Set(5)
+	comet.Seed(1)
+
+	comet.Update(hw, []string{"a"}, 100000000)
+
+	// the head is at LED 15, the tail gets darker behind it and ends after five LEDs
+	last, _, _ := hw.Led.GetColor("a", 14)
+	for pos := 13; pos >= 9; pos-- {
+		r, _, _ := hw.Led.GetColor("a", pos)
+		if r >= last {
+			t.Errorf("brightness of LED %d is %d, expected less than %d", pos, r, last)
+		}
+		last = r
+	}
+}
+
+func TestCometRandomDecayIsStable(t *testing.T) {
+	hw := newTestHardware(t)
+	parts := []string{"a", "b"}
+
+	// two comets with long tails, so that the tail of each comet is passed by the head of the other one
+	comet := NewCometEffect()
+	comet.speed.Set(1)
+	comet.tailLength.Set(40)
+	comet.decay.Set(0)
+	comet.count.Set(2)
+	comet.randomDecay.Set(true)
+	comet.Seed(1)
+
+	// move the heads just behind the next LED, so that the tail of the other comet is brighter there, and then only a
+	// tiny bit further: the tails must not change
+	comet.Update(hw, parts, 66666667)
+	first := getColors(hw, parts)
+	for i := 0; i < 10; i++ {
+		comet.Update(hw, parts, 1000)
+	}
+	if next := getColors(hw, parts); !bytes.Equal(first, next) {
+		t.Error("tails change while the comets are not moving")
+	}
+}
@@ -23,20 +23,13 @@ type Effect interface {
 	Parameters() []*parameters.Parameter
 }
 
-// NewEffect returns a new effect of specified effect type (or nil)
-func NewEffect(effecttype string) Effect {
-	if effecttype == Calibration {
-		return NewCalibrationEffect()
-	} else if effecttype == SingleColor {
-		return NewSingleColorEffect()
-	} else if effecttype == Blink {
-		return NewBlinkEffect()
-	} else if effecttype == Stripes {
-		return NewStripesEffect()
-	} else if effecttype == Rainbow {
-		return NewRainbowEffect()
-	}
-	return nil
+// RandomEffect is implemented by effects that use random numbers. Each effect has its own random source, which is
+// seeded with the current time.
+type RandomEffect interface {
+	Effect
+
+	// Seed resets the random source, so that the effect creates the same output again (e.g. for previews)
+	Seed(seed int64)
 }
 
 // EffectJSON is the JSON format for effects
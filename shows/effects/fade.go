@@ -0,0 +1,170 @@
+package effects
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// Fade is the fade effect
+const Fade = "fade"
+
+// EasingLinear, EasingSine, EasingExponential and EasingInOut are the values of the `easing` parameter of the fade
+// effect, larger values are treated like EasingInOut
+const (
+	EasingLinear = iota
+	EasingSine
+	EasingExponential
+	EasingInOut
+)
+
+// minFadePeriod is the shortest period of the fade effect in ms
+const minFadePeriod = 10
+
+// perceptualGamma is the gamma value for which evenly spaced color values are perceived as evenly spaced brightness
+const perceptualGamma = 2.2
+
+func init() {
+	Register(EffectInfo{
+		Type:        Fade,
+		Name:        "Fade",
+		Description: "Fades smoothly between two colors",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewFadeEffect() },
+	})
+}
+
+// FadeEffect is a effect that fades between two colors
+type FadeEffect struct {
+	colorPrimary   *parameters.Parameter
+	colorSecondary *parameters.Parameter
+	period         *parameters.Parameter
+	hold           *parameters.Parameter
+	easing         *parameters.Parameter
+
+	nsSinceLastStart int64
+}
+
+// NewFadeEffect returns a new fade effect
+func NewFadeEffect() *FadeEffect {
+	fade := FadeEffect{}
+
+	fade.colorPrimary = parameters.NewParameter("colorPrimary", parameters.Color, "Primary color")
+	fade.colorSecondary = parameters.NewParameter("colorSecondary", parameters.Color, "Secondary color")
+	fade.period = parameters.NewParameter("period", parameters.IntegerGreaterOrEqualZero, "Period")
+	fade.hold = parameters.NewParameter("hold", parameters.Percent, "Hold time")
+	fade.easing = parameters.NewParameter("easing", parameters.IntegerGreaterOrEqualZero, "Easing")
+
+	setDefault(fade.period, 4000)
+	setDefault(fade.hold, 0)
+	setDefault(fade.easing, EasingSine)
+
+	return &fade
+}
+
+// Type returns "fade"
+func (e *FadeEffect) Type() string {
+	return Fade
+}
+
+// Name returns "Fade"
+func (e *FadeEffect) Name() string {
+	return "Fade"
+}
+
+// Update decides about the changes that are caused by the effect for a certain timestep.
+func (e *FadeEffect) Update(hw *hardware.Hardware, parts []string, nanoseconds int64) {
+	colorPrimary := e.colorPrimary.Get().(color.NRGBA)
+	colorSecondary := e.colorSecondary.Get().(color.NRGBA)
+	period := e.period.Get().(int)
+	hold := e.hold.Get().(int)
+	easing := e.easing.Get().(int)
+
+	if period < minFadePeriod {
+		period = minFadePeriod
+	}
+	if easing > EasingInOut {
+		easing = EasingInOut
+	}
+
+	// length of one cycle from the primary color to the secondary color and back
+	interval := int64(period) * 1000000
+
+	// the hold time is split between both colors, the rest of the cycle is used for fading
+	intervalHold := mapPercent(0, interval, hold) / 2
+	intervalFade := (interval - 2*intervalHold) / 2
+	if intervalFade < 1 {
+		intervalFade = 1
+	}
+
+	// get time since last start of the cycle
+	e.nsSinceLastStart = (e.nsSinceLastStart + nanoseconds) % interval
+	t := e.nsSinceLastStart
+
+	// hold primary color, fade to secondary color, hold secondary color and fade back
+	var factor float64
+	switch {
+	case t < intervalHold:
+		factor = 0
+	case t < intervalHold+intervalFade:
+		factor = ease(easing, float64(t-intervalHold)/float64(intervalFade))
+	case t < 2*intervalHold+intervalFade:
+		factor = 1
+	default:
+		factor = ease(easing, 1-float64(t-2*intervalHold-intervalFade)/float64(intervalFade))
+	}
+
+	mixed := mixColorsPerceptual(colorPrimary, colorSecondary, factor, hw.Led.Gamma())
+
+	for _, part := range parts {
+		hw.Led.SetColorAllPart(part, mixed.R, mixed.G, mixed.B)
+	}
+}
+
+// ease maps the progress of a fade (0 - 1) to the share of the second color (0 - 1)
+func ease(easing int, t float64) float64 {
+	t = math.Max(0, math.Min(1, t))
+
+	switch easing {
+	case EasingSine:
+		return (1 - math.Cos(math.Pi*t)) / 2
+	case EasingExponential:
+		return (math.Pow(2, 10*t) - 1) / (math.Pow(2, 10) - 1)
+	case EasingInOut:
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 3)/2
+	default:
+		return t
+	}
+}
+
+// mixColorsPerceptual interpolates between two colors so that the perceived brightness changes evenly. The colors are
+// corrected with `gamma` by the hardware, so they are mixed in the space where this correction results in even steps.
+// For the usual gamma of 2.2, this is the same as mixing the color values directly.
+func mixColorsPerceptual(from color.NRGBA, to color.NRGBA, factor float64, gamma float64) color.NRGBA {
+	exponent := gamma / perceptualGamma
+
+	mix := func(a, b uint8) uint8 {
+		start := math.Pow(float64(a)/255, exponent)
+		end := math.Pow(float64(b)/255, exponent)
+		value := start + (end-start)*factor
+		return uint8(math.Round(255 * math.Pow(value, 1/exponent)))
+	}
+
+	return color.NRGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+}
+
+// Parameters returns the list of parameters
+func (e *FadeEffect) Parameters() []*parameters.Parameter {
+	data := make([]*parameters.Parameter, 5)
+	data[0] = e.colorPrimary
+	data[1] = e.colorSecondary
+	data[2] = e.period
+	data[3] = e.hold
+	data[4] = e.easing
+	return data
+}
@@ -0,0 +1,43 @@
+package effects
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestFadePeriod(t *testing.T) {
+	hw := newTestHardware(t)
+
+	fade := NewFadeEffect()
+	fade.colorPrimary.Set(color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+	fade.colorSecondary.Set(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	fade.period.Set(1000)
+
+	// after half of the period, the secondary color is reached
+	fade.Update(hw, []string{"a"}, 500000000)
+	if r, g, b := hw.Led.GetColor("a", 0); r != 200 || g != 100 || b != 50 {
+		t.Errorf("color after half of the period is %d, %d, %d, expected the secondary color", r, g, b)
+	}
+
+	// and after the whole period the primary color again
+	fade.Update(hw, []string{"a"}, 500000000)
+	if r, g, b := hw.Led.GetColor("a", 0); r != 0 || g != 0 || b != 0 {
+		t.Errorf("color after the period is %d, %d, %d, expected the primary color", r, g, b)
+	}
+}
+
+func TestFadeUnknownEasing(t *testing.T) {
+	hw := newTestHardware(t)
+
+	render := func(easing int) []byte {
+		fade := NewFadeEffect()
+		fade.colorSecondary.Set(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		fade.easing.Set(easing)
+		return renderFrames(hw, fade, 50)
+	}
+
+	if !bytes.Equal(render(100), render(EasingInOut)) {
+		t.Error("unknown easing is not treated like ease-in-out")
+	}
+}
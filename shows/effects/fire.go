@@ -0,0 +1,181 @@
+package effects
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// Fire is the fire effect
+const Fire = "fire"
+
+// fireMaxStepsPerUpdate is the maximum number of simulation steps that are calculated in one update
+const fireMaxStepsPerUpdate = 10
+
+func init() {
+	Register(EffectInfo{
+		Type:        Fire,
+		Name:        "Fire",
+		Description: "Flames that rise from the beginning of the parts, every flame (like each horn) needs its own group",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewFireEffect() },
+	})
+}
+
+// FireEffect is a effect that simulates fire. Every LED has a heat value: the heat cools down, rises up and new sparks
+// are ignited at the bottom. The heat is mapped to a palette of three colors. The parts are handled as one strip, so
+// the flames rise from the first LED of the first part (or the last LED of the last part if reversed). Parts that
+// should burn on their own, like the two horns, need one group each.
+type FireEffect struct {
+	colorCold *parameters.Parameter
+	colorWarm *parameters.Parameter
+	colorHot  *parameters.Parameter
+	speed     *parameters.Parameter
+	cooling   *parameters.Parameter
+	sparking  *parameters.Parameter
+	reversed  *parameters.Parameter
+
+	heat         []float64
+	nsSinceStep  int64
+	randomSource *rand.Rand
+}
+
+// NewFireEffect returns a new fire effect
+func NewFireEffect() *FireEffect {
+	fire := FireEffect{}
+
+	fire.colorCold = parameters.NewParameter("colorCold", parameters.Color, "Color of cold flames")
+	fire.colorWarm = parameters.NewParameter("colorWarm", parameters.Color, "Color of warm flames")
+	fire.colorHot = parameters.NewParameter("colorHot", parameters.Color, "Color of hot flames")
+	fire.speed = parameters.NewParameter("speed", parameters.Percent, "Speed")
+	fire.cooling = parameters.NewParameter("cooling", parameters.Percent, "Cooling")
+	fire.sparking = parameters.NewParameter("sparking", parameters.Percent, "Sparking")
+	fire.reversed = parameters.NewParameter("reversed", parameters.Boolean, "Reversed (flames rise from the end of the last part)")
+
+	// without heat the LEDs are off, then the flames go from red over orange to pale yellow like a real fire
+	setDefault(fire.colorCold, color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	setDefault(fire.colorWarm, color.NRGBA{R: 255, G: 160, B: 0, A: 255})
+	setDefault(fire.colorHot, color.NRGBA{R: 255, G: 255, B: 200, A: 255})
+	setDefault(fire.speed, 50)
+	setDefault(fire.cooling, 50)
+	setDefault(fire.sparking, 50)
+
+	fire.randomSource = newRandomSource()
+
+	return &fire
+}
+
+// Type returns "fire"
+func (e *FireEffect) Type() string {
+	return Fire
+}
+
+// Name returns "Fire"
+func (e *FireEffect) Name() string {
+	return "Fire"
+}
+
+// Seed resets the random source with the given seed and starts again without heat
+func (e *FireEffect) Seed(seed int64) {
+	e.randomSource.Seed(seed)
+	e.heat = nil
+	e.nsSinceStep = 0
+}
+
+// Update decides about the changes that are caused by the effect for a certain timestep.
+func (e *FireEffect) Update(hw *hardware.Hardware, parts []string, nanoseconds int64) {
+	palette := []color.NRGBA{
+		{R: 0, G: 0, B: 0},
+		e.colorCold.Get().(color.NRGBA),
+		e.colorWarm.Get().(color.NRGBA),
+		e.colorHot.Get().(color.NRGBA),
+	}
+	speed := e.speed.Get().(int)
+	cooling := e.cooling.Get().(int)
+	sparking := e.sparking.Get().(int)
+	reversed := e.reversed.Get().(bool)
+
+	numLeds := hw.Led.GetNumLedsMultiPart(parts)
+	if numLeds == 0 {
+		return
+	}
+	if len(e.heat) != numLeds {
+		e.heat = make([]float64, numLeds)
+	}
+
+	// the simulation runs with a fixed number of steps per second, so that the look does not depend on the frame rate
+	stepsPerSecond := mapPercent(10.0, 120.0, speed)
+	interval := int64(1000000000.0 / stepsPerSecond)
+
+	e.nsSinceStep += nanoseconds
+	for steps := 0; e.nsSinceStep >= interval; steps++ {
+		e.nsSinceStep -= interval
+
+		// do not try to catch up after long pauses
+		if steps >= fireMaxStepsPerUpdate {
+			e.nsSinceStep = 0
+			break
+		}
+
+		e.step(cooling, sparking)
+	}
+
+	// the heat starts at the first LED, or at the last one if the effect is reversed
+	for i := 0; i < numLeds; i++ {
+		pos := i
+		if reversed {
+			pos = numLeds - 1 - i
+		}
+
+		heatColor := getPaletteColor(palette, e.heat[i])
+		hw.Led.SetColorMultiPart(parts, pos, heatColor.R, heatColor.G, heatColor.B, false)
+	}
+}
+
+// step runs one step of the heat simulation
+func (e *FireEffect) step(cooling int, sparking int) {
+	numLeds := len(e.heat)
+
+	// cool down every LED a little bit, short strips cool down faster so that the flames have a similar height
+	maxCooling := (mapPercent(20.0, 200.0, cooling)*10.0/float64(numLeds) + 2.0) / 255.0
+	for i := range e.heat {
+		e.heat[i] -= e.randomSource.Float64() * maxCooling
+		if e.heat[i] < 0 {
+			e.heat[i] = 0
+		}
+	}
+
+	// the heat rises up and diffuses a little bit
+	for i := numLeds - 1; i >= 2; i-- {
+		e.heat[i] = (e.heat[i-1] + 2*e.heat[i-2]) / 3
+	}
+
+	// ignite new sparks near the bottom
+	if e.randomSource.Intn(100) < sparking {
+		sparkArea := numLeds
+		if sparkArea > 7 {
+			sparkArea = 7
+		}
+
+		pos := e.randomSource.Intn(sparkArea)
+		e.heat[pos] += 0.6 + 0.4*e.randomSource.Float64()
+		if e.heat[pos] > 1 {
+			e.heat[pos] = 1
+		}
+	}
+}
+
+// Parameters returns the list of parameters
+func (e *FireEffect) Parameters() []*parameters.Parameter {
+	data := make([]*parameters.Parameter, 7)
+	data[0] = e.colorCold
+	data[1] = e.colorWarm
+	data[2] = e.colorHot
+	data[3] = e.speed
+	data[4] = e.cooling
+	data[5] = e.sparking
+	data[6] = e.reversed
+	return data
+}
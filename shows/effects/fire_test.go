@@ -0,0 +1,61 @@
+package effects
+
+import (
+	"testing"
+
+	"github.com/light-bull/lightbull/hardware"
+)
+
+// brightness returns the sum of all color values of the LEDs from `first` to `last` of a part
+func brightness(hw *hardware.Hardware, part string, first int, last int) int {
+	sum := 0
+	for pos := first; pos <= last; pos++ {
+		r, g, b := hw.Led.GetColor(part, pos)
+		sum += int(r) + int(g) + int(b)
+	}
+	return sum
+}
+
+// TestFireRisesOverAllParts checks that a group with two parts has one flame that rises from the beginning of the
+// parts, not one flame per part
+func TestFireRisesOverAllParts(t *testing.T) {
+	for _, reversed := range []bool{false, true} {
+		hw := newTestHardware(t)
+
+		// "a" has 30 LEDs and "b" 20, the flame starts in "a", so that "b" is far away from the base
+		parts := []string{"a", "b"}
+		if reversed {
+			parts = []string{"b", "a"}
+		}
+
+		fire := NewFireEffect()
+		fire.sparking.Set(100)
+		fire.cooling.Set(100)
+		fire.reversed.Set(reversed)
+		fire.Seed(1)
+
+		// one second of fire
+		for i := 0; i < 50; i++ {
+			fire.Update(hw, parts, 20000000)
+		}
+
+		// the base is at the beginning of "a" and the tip at the end of "b" (or the other way round if reversed)
+		numLedsA, numLedsB := hw.Led.GetNumLeds("a"), hw.Led.GetNumLeds("b")
+		base, tip := brightness(hw, "a", 0, 4), brightness(hw, "b", numLedsB-5, numLedsB-1)
+		secondBase := brightness(hw, "b", 0, 4)
+		if reversed {
+			base, tip = brightness(hw, "a", numLedsA-5, numLedsA-1), brightness(hw, "b", 0, 4)
+			secondBase = brightness(hw, "b", numLedsB-5, numLedsB-1)
+		}
+
+		if base <= tip {
+			t.Errorf("flames do not rise from the base of the parts (reversed: %t)", reversed)
+		}
+		if tip != 0 {
+			t.Errorf("flames reach the tip of the parts (reversed: %t)", reversed)
+		}
+		if secondBase != 0 {
+			t.Errorf("second part has its own flame (reversed: %t)", reversed)
+		}
+	}
+}
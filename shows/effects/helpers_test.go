@@ -0,0 +1,47 @@
+package effects
+
+import (
+	"testing"
+
+	"github.com/light-bull/lightbull/hardware"
+)
+
+// newTestHardware returns hardware with LEDs in memory and the parts "a" and "b"
+func newTestHardware(t *testing.T) *hardware.Hardware {
+	layout := hardware.NewPartLayout()
+	layout.AddPart("a", 0, 29)
+	layout.AddPart("b", 30, 49)
+
+	led := hardware.NewLED()
+	led.SetPartLayout(layout)
+	if err := led.InitWithDriver(hardware.DriverMemory); err != nil {
+		t.Fatal(err)
+	}
+
+	return &hardware.Hardware{Led: led}
+}
+
+// renderFrames runs the effect for `numFrames` frames at 50 FPS and returns the colors of all frames
+func renderFrames(hw *hardware.Hardware, effect Effect, numFrames int) []byte {
+	parts := []string{"a", "b"}
+	var frames []byte
+
+	for i := 0; i < numFrames; i++ {
+		effect.Update(hw, parts, 20000000)
+		frames = append(frames, getColors(hw, parts)...)
+	}
+
+	return frames
+}
+
+// getColors returns the colors of all LEDs of the concatenated parts
+func getColors(hw *hardware.Hardware, parts []string) []byte {
+	var colors []byte
+	for _, part := range parts {
+		for pos := 0; pos < hw.Led.GetNumLeds(part); pos++ {
+			r, g, b := hw.Led.GetColor(part, pos)
+			colors = append(colors, r, g, b)
+		}
+	}
+	return colors
+}
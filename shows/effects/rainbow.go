@@ -5,6 +5,19 @@ import (
 	"github.com/light-bull/lightbull/shows/parameters"
 )
 
+// Rainbow is a rainbow effect
+const Rainbow = "rainbow"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Rainbow,
+		Name:        "Rainbow",
+		Description: "Moving rainbow over all LEDs",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewRainbowEffect() },
+	})
+}
+
 // RainbowEffect is a effect that draws a moving rainbow
 type RainbowEffect struct {
 	speed    *parameters.Parameter
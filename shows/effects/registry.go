@@ -0,0 +1,113 @@
+package effects
+
+import (
+	"sort"
+
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+const (
+	// CategoryStatic contains effects with constant colors
+	CategoryStatic = "static"
+
+	// CategoryAnimated contains effects that change over time
+	CategoryAnimated = "animated"
+
+	// CategoryTools contains effects that help to set up the LEDs
+	CategoryTools = "tools"
+)
+
+// EffectInfo describes an effect type. Every effect registers its info in the init function of its file.
+type EffectInfo struct {
+	// Type is the identifier like "blink", it needs to match Effect.Type
+	Type string `json:"type"`
+
+	// Name is a nice name like "Blink"
+	Name string `json:"name"`
+
+	// Description is a short explanation for the UI
+	Description string `json:"description"`
+
+	// Category is used to group the effects in the UI, like CategoryAnimated
+	Category string `json:"category"`
+
+	// Parameters describes the parameters of the effect, it is filled by Register
+	Parameters []ParameterInfo `json:"parameters"`
+
+	// New creates a new effect of this type
+	New func() Effect `json:"-"`
+}
+
+// ParameterInfo describes a parameter of an effect type
+type ParameterInfo struct {
+	Key     string              `json:"key"`
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Default parameters.DataType `json:"default"`
+}
+
+var registry = make(map[string]*EffectInfo)
+
+// Register makes an effect type available. It panics if the type is registered twice or the info is incomplete, since
+// this is a programming error.
+func Register(info EffectInfo) {
+	if info.Type == "" || info.Name == "" || info.New == nil {
+		panic("incomplete registration of effect " + info.Type)
+	}
+	if _, exists := registry[info.Type]; exists {
+		panic("effect " + info.Type + " is registered twice")
+	}
+
+	// the parameter schema is taken from a new effect, so it cannot differ from the real parameters
+	effect := info.New()
+	if effect.Type() != info.Type || effect.Name() != info.Name {
+		panic("effect " + info.Type + " returns a different type or name than registered")
+	}
+
+	info.Parameters = make([]ParameterInfo, 0, len(effect.Parameters()))
+	for _, parameter := range effect.Parameters() {
+		info.Parameters = append(info.Parameters, ParameterInfo{
+			Key:     parameter.Key,
+			Name:    parameter.Name,
+			Type:    parameter.Type(),
+			Default: parameter.Default(),
+		})
+	}
+
+	registry[info.Type] = &info
+}
+
+// NewEffect returns a new effect of specified effect type (or nil)
+func NewEffect(effecttype string) Effect {
+	info, exists := registry[effecttype]
+	if !exists {
+		return nil
+	}
+	return info.New()
+}
+
+// GetEffects returns the type and name of all effects
+func GetEffects() map[string]string {
+	effectNames := make(map[string]string)
+	for effectType, info := range registry {
+		effectNames[effectType] = info.Name
+	}
+	return effectNames
+}
+
+// GetEffectInfos returns the descriptions of all effects sorted by category and name
+func GetEffectInfos() []*EffectInfo {
+	infos := make([]*EffectInfo, 0, len(registry))
+	for _, info := range registry {
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Category != infos[j].Category {
+			return infos[i].Category < infos[j].Category
+		}
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
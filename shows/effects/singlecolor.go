@@ -7,6 +7,19 @@ import (
 	"github.com/light-bull/lightbull/shows/parameters"
 )
 
+// SingleColor is the single color effect
+const SingleColor = "singlecolor"
+
+func init() {
+	Register(EffectInfo{
+		Type:        SingleColor,
+		Name:        "Single Color",
+		Description: "All LEDs in one color",
+		Category:    CategoryStatic,
+		New:         func() Effect { return NewSingleColorEffect() },
+	})
+}
+
 // SingleColorEffect is a effect that lets the LEDs show one color
 type SingleColorEffect struct {
 	color *parameters.Parameter
@@ -7,6 +7,19 @@ import (
 	"github.com/light-bull/lightbull/shows/parameters"
 )
 
+// Stripes is the stripes effect
+const Stripes = "stripes"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Stripes,
+		Name:        "Stripes",
+		Description: "Moving stripes in two colors",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewStripesEffect() },
+	})
+}
+
 // StripesEffect is a effect that draws moving stripes in one color
 type StripesEffect struct {
 	colorPrimary   *parameters.Parameter
@@ -0,0 +1,145 @@
+package effects
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/light-bull/lightbull/hardware"
+	"github.com/light-bull/lightbull/shows/parameters"
+)
+
+// Twinkle is the twinkle effect
+const Twinkle = "twinkle"
+
+func init() {
+	Register(EffectInfo{
+		Type:        Twinkle,
+		Name:        "Twinkle",
+		Description: "Random LEDs fade in and out on top of a base color",
+		Category:    CategoryAnimated,
+		New:         func() Effect { return NewTwinkleEffect() },
+	})
+}
+
+// TwinkleEffect is a effect that lets random LEDs fade in and out
+type TwinkleEffect struct {
+	colorBase     *parameters.Parameter
+	colorTwinkle  *parameters.Parameter
+	density       *parameters.Parameter
+	fadeTime      *parameters.Parameter
+	colorVariance *parameters.Parameter
+
+	twinkles     []twinkle
+	randomSource *rand.Rand
+}
+
+// twinkle is the state of a single LED
+type twinkle struct {
+	// progress goes from 0 to 1 while the LED fades in and out, 0 means that the LED is off
+	progress float64
+	color    color.NRGBA
+}
+
+// NewTwinkleEffect returns a new twinkle effect
+func NewTwinkleEffect() *TwinkleEffect {
+	twinkle := TwinkleEffect{}
+
+	twinkle.colorBase = parameters.NewParameter("colorBase", parameters.Color, "Base color")
+	twinkle.colorTwinkle = parameters.NewParameter("colorTwinkle", parameters.Color, "Twinkle color")
+	twinkle.density = parameters.NewParameter("density", parameters.Percent, "Density")
+	twinkle.fadeTime = parameters.NewParameter("fadeTime", parameters.Percent, "Fade time")
+	twinkle.colorVariance = parameters.NewParameter("colorVariance", parameters.Percent, "Color variance")
+
+	setDefault(twinkle.colorTwinkle, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	setDefault(twinkle.density, 30)
+	setDefault(twinkle.fadeTime, 30)
+	setDefault(twinkle.colorVariance, 0)
+
+	twinkle.randomSource = newRandomSource()
+
+	return &twinkle
+}
+
+// Type returns "twinkle"
+func (e *TwinkleEffect) Type() string {
+	return Twinkle
+}
+
+// Name returns "Twinkle"
+func (e *TwinkleEffect) Name() string {
+	return "Twinkle"
+}
+
+// Seed resets the random source with the given seed and turns off all twinkles
+func (e *TwinkleEffect) Seed(seed int64) {
+	e.randomSource.Seed(seed)
+	e.twinkles = nil
+}
+
+// Update decides about the changes that are caused by the effect for a certain timestep.
+func (e *TwinkleEffect) Update(hw *hardware.Hardware, parts []string, nanoseconds int64) {
+	colorBase := e.colorBase.Get().(color.NRGBA)
+	colorTwinkle := e.colorTwinkle.Get().(color.NRGBA)
+	density := e.density.Get().(int)
+	fadeTime := e.fadeTime.Get().(int)
+	colorVariance := e.colorVariance.Get().(int)
+
+	numLeds := hw.Led.GetNumLedsMultiPart(parts)
+	if len(e.twinkles) != numLeds {
+		e.twinkles = make([]twinkle, numLeds)
+	}
+
+	// time for fading in and out
+	duration := mapPercent(int64(100000000), 5000000000, fadeTime)
+	step := float64(nanoseconds) / float64(duration)
+
+	// at 100% density, half of the LEDs are lit on average. a twinkle lasts `duration`, so a LED that is off needs to
+	// start a new one with this probability to keep this share of lit LEDs
+	share := mapPercent(0.0, 0.5, density)
+	startProbability := share / (1 - share) * step
+
+	for i := range e.twinkles {
+		current := &e.twinkles[i]
+
+		if current.progress > 0 {
+			current.progress += step
+			if current.progress >= 1 {
+				current.progress = 0
+			}
+		} else if e.randomSource.Float64() < startProbability {
+			current.progress = step
+			current.color = e.getTwinkleColor(colorTwinkle, colorVariance)
+		}
+
+		// fade in during the first half and fade out during the second half
+		brightness := math.Sin(math.Pi * current.progress)
+		mixed := getPaletteColor([]color.NRGBA{colorBase, current.color}, brightness)
+
+		hw.Led.SetColorMultiPart(parts, i, mixed.R, mixed.G, mixed.B, false)
+	}
+}
+
+// getTwinkleColor returns the twinkle color with a random hue shift, at 100% variance the hue can be anything
+func (e *TwinkleEffect) getTwinkleColor(colorTwinkle color.NRGBA, colorVariance int) color.NRGBA {
+	if colorVariance == 0 {
+		return colorTwinkle
+	}
+
+	h, s, v := rgb2hsv(colorTwinkle.R, colorTwinkle.G, colorTwinkle.B)
+	shift := int(math.Round((e.randomSource.Float64()*2 - 1) * mapPercent(0.0, 180.0, colorVariance)))
+
+	r, g, b := hsv2rgb(moduloInt(h+shift, 360), s, v)
+	return color.NRGBA{R: r, G: g, B: b, A: 255}
+}
+
+// Parameters returns the list of parameters
+func (e *TwinkleEffect) Parameters() []*parameters.Parameter {
+	data := make([]*parameters.Parameter, 5)
+	data[0] = e.colorBase
+	data[1] = e.colorTwinkle
+	data[2] = e.density
+	data[3] = e.fadeTime
+	data[4] = e.colorVariance
+	return data
+}
@@ -0,0 +1,31 @@
+package effects
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTwinkleSeed(t *testing.T) {
+	hw := newTestHardware(t)
+
+	newTwinkle := func(seed int64) *TwinkleEffect {
+		twinkle := NewTwinkleEffect()
+		twinkle.colorVariance.Set(50)
+		twinkle.Seed(seed)
+		return twinkle
+	}
+
+	first := renderFrames(hw, newTwinkle(42), 200)
+	second := renderFrames(hw, newTwinkle(42), 200)
+	other := renderFrames(hw, newTwinkle(43), 200)
+
+	if !bytes.Equal(first, second) {
+		t.Error("frames differ for the same seed")
+	}
+	if bytes.Equal(first, other) {
+		t.Error("frames are the same for different seeds")
+	}
+	if bytes.Count(first, []byte{0}) == len(first) {
+		t.Error("no LED was lit")
+	}
+}
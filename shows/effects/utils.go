@@ -1,7 +1,12 @@
 package effects
 
 import (
+	"image/color"
 	"math"
+	"math/rand"
+	"time"
+
+	"github.com/light-bull/lightbull/shows/parameters"
 )
 
 // moduloFloat64 implements math.Mod with proper negative number support
@@ -47,6 +52,39 @@ func mapPercent[K int | int64 | float64](min K, max K, percent int) K {
 	return min + ((max - min) * K(percent) / 100)
 }
 
+// newRandomSource returns a random source for an effect that is seeded with the current time
+func newRandomSource() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// setDefault sets the current and the default value of a new parameter
+func setDefault(parameter *parameters.Parameter, value interface{}) {
+	parameter.Set(value)
+	parameter.SetDefault()
+}
+
+// getPaletteColor returns the color for a value between 0 and 1. The colors of the palette are evenly distributed
+// over this range and the color is interpolated between the two nearest ones.
+func getPaletteColor(palette []color.NRGBA, value float64) color.NRGBA {
+	value = math.Max(0, math.Min(1, value))
+
+	pos := value * float64(len(palette)-1)
+	index := int(pos)
+	if index >= len(palette)-1 {
+		return palette[len(palette)-1]
+	}
+
+	fraction := pos - float64(index)
+	from := palette[index]
+	to := palette[index+1]
+
+	interpolate := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*fraction))
+	}
+
+	return color.NRGBA{R: interpolate(from.R, to.R), G: interpolate(from.G, to.G), B: interpolate(from.B, to.B), A: 255}
+}
+
 // hueToRGB converts HSV to RGB
 // H: 0-360, S: 0-100, V: 0-100
 // For the HSV input, S and V are 255 and H is variable.
@@ -91,3 +129,34 @@ func hsv2rgb(h int, s int, v int) (r byte, g byte, b byte) {
 	}
 	return byte(result[0]), byte(result[1]), byte(result[2])
 }
+
+// rgb2hsv converts RGB to HSV with the same ranges as hsv2rgb
+// H: 0-360, S: 0-100, V: 0-100
+func rgb2hsv(r byte, g byte, b byte) (h int, s int, v int) {
+	rTmp := float64(r) / 255
+	gTmp := float64(g) / 255
+	bTmp := float64(b) / 255
+
+	max := math.Max(rTmp, math.Max(gTmp, bTmp))
+	min := math.Min(rTmp, math.Min(gTmp, bTmp))
+	delta := max - min
+
+	var hTmp float64
+	switch {
+	case delta == 0:
+		hTmp = 0
+	case max == rTmp:
+		hTmp = 60 * moduloFloat64((gTmp-bTmp)/delta, 6)
+	case max == gTmp:
+		hTmp = 60 * ((bTmp-rTmp)/delta + 2)
+	default:
+		hTmp = 60 * ((rTmp-gTmp)/delta + 4)
+	}
+
+	sTmp := 0.0
+	if max > 0 {
+		sTmp = delta / max
+	}
+
+	return moduloInt(int(math.Round(hTmp)), 360), int(math.Round(sTmp * 100)), int(math.Round(max * 100))
+}
@@ -3,6 +3,9 @@ package shows
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
 
 	"github.com/google/uuid"
 	"github.com/light-bull/lightbull/hardware"
@@ -17,9 +20,21 @@ type Group struct {
 
 	parts []string
 
+	failure *EffectFailure
+
 	// FIXME: mux!
 }
 
+// EffectFailure describes an effect that panicked. The effect is disabled until the parts or the effect of the group are
+// set again.
+type EffectFailure struct {
+	VisualID uuid.UUID `json:"visualId"`
+	GroupID  uuid.UUID `json:"groupId"`
+	Effect   string    `json:"effect"`
+	Parts    []string  `json:"parts"`
+	Error    string    `json:"error"`
+}
+
 type groupJSON struct {
 	ID     uuid.UUID           `json:"id"`
 	Parts  []string            `json:"parts"`
@@ -76,14 +91,15 @@ func (group *Group) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// Parts returns the LED parts that are configured for this effect.
+// Parts returns the LED parts and zones that are configured for this effect.
 func (group *Group) Parts() []string {
 	return group.parts
 }
 
-// SetParts changes the LED parts that are configured for this effect.
+// SetParts changes the LED parts and zones that are configured for this effect.
 func (group *Group) SetParts(parts []string) error {
 	group.parts = parts
+	group.failure = nil
 
 	// TODO: check that part is only configured for one effect in a visual
 
@@ -92,8 +108,9 @@ func (group *Group) SetParts(parts []string) error {
 
 // SetEffect changes the effect type for this group
 func (group *Group) SetEffect(effecttype string) error {
-	// no change -> no nothing
+	// no change -> only enable the effect again if it failed
 	if group.Effect != nil && effecttype == group.Effect.Type() {
+		group.failure = nil
 		return nil
 	}
 
@@ -104,12 +121,52 @@ func (group *Group) SetEffect(effecttype string) error {
 	}
 
 	group.Effect = effect
+	group.failure = nil
 	return nil
 }
 
+// Failure returns the failure of the effect if it was disabled or nil.
+func (group *Group) Failure() *EffectFailure {
+	return group.failure
+}
+
 // Update decides about the changes that are caused by the group/effect for a certain timestep.
-func (group *Group) Update(hw *hardware.Hardware, nanoseconds int64) {
-	if group.Effect != nil {
-		group.Effect.Update(hw, group.parts, nanoseconds)
+// If the effect panics, it is disabled, its parts are turned off and the failure is returned.
+func (group *Group) Update(hw *hardware.Hardware, nanoseconds int64) (failure *EffectFailure) {
+	if group.Effect == nil || group.failure != nil {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Effect %s of group %s failed, disabling it: %v\n%s", group.Effect.Type(), group.ID, r, debug.Stack())
+
+			group.failure = &EffectFailure{
+				GroupID: group.ID,
+				Effect:  group.Effect.Type(),
+				Parts:   group.parts,
+				Error:   fmt.Sprint(r),
+			}
+			group.blackout(hw)
+
+			failure = group.failure
+		}
+	}()
+
+	// zones are passed as their parts, so that the effects only need to know parts
+	group.Effect.Update(hw, hw.Led.ExpandParts(group.parts), nanoseconds)
+	return nil
+}
+
+// blackout turns off all LEDs of the parts of the group
+func (group *Group) blackout(hw *hardware.Hardware) {
+	for _, part := range hw.Led.ExpandParts(group.parts) {
+		if !hw.Led.HasPart(part) {
+			continue
+		}
+
+		for i := 0; i < hw.Led.GetNumLeds(part); i++ {
+			hw.Led.SetColor(part, i, 0, 0, 0)
+		}
 	}
 }
@@ -139,11 +139,33 @@ func (parameter *Parameter) FillLinkedParametersAfterUnmarshall(mapping map[uuid
 	return nil
 }
 
+// Type returns the datatype of the parameter (like "color")
+func (parameter *Parameter) Type() string {
+	return parameter.cur.Type()
+}
+
+// Default returns the default value
+func (parameter *Parameter) Default() DataType {
+	return parameter.def
+}
+
 // Get returns the currently set value
 func (parameter *Parameter) Get() interface{} {
 	return parameter.cur.Get()
 }
 
+// Set sets a new value, it needs to match the datatype of the parameter
+func (parameter *Parameter) Set(value interface{}) error {
+	err := parameter.cur.Set(value)
+	if err != nil {
+		return err
+	}
+
+	parameter.updateLinkedParameters()
+
+	return nil
+}
+
 // SetFromJSON sets a new value from the JSON data
 func (parameter *Parameter) SetFromJSON(data []byte) error {
 	err := parameter.cur.UnmarshalJSON(data)
@@ -145,11 +145,13 @@ func (show *Show) hasVisual(visual *Visual) bool {
 }
 
 // Update decides about the changes that are caused by the current visual for a certain timestep.
-func (show *Show) Update(hw *hardware.Hardware, nanoseconds int64) {
+// It returns the effects that failed during this update.
+func (show *Show) Update(hw *hardware.Hardware, nanoseconds int64) []*EffectFailure {
 	visual := show.CurrentVisual()
 	if visual != nil {
-		visual.Update(hw, nanoseconds)
-	} else {
-		hw.Led.SetColorAll(0, 0, 0)
+		return visual.Update(hw, nanoseconds)
 	}
+
+	hw.Led.SetColorAll(0, 0, 0)
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/light-bull/lightbull/hardware"
 	"github.com/light-bull/lightbull/shows/parameters"
 )
 
@@ -220,3 +221,100 @@ func (showCollection *ShowCollection) FindParameter(idStr string) (*Show, *Visua
 
 	return nil, nil, nil, nil
 }
+
+// CheckParts validates that all parts and zones that are used by groups exist in the given layout
+func (showCollection *ShowCollection) CheckParts(layout *hardware.PartLayout) error {
+	showCollection.mux.Lock()
+	defer showCollection.mux.Unlock()
+
+	for _, show := range showCollection.shows {
+		for _, visual := range show.Visuals() {
+			for _, group := range visual.Groups() {
+				for _, part := range group.Parts() {
+					if !layout.HasPart(part) && !layout.HasZone(part) {
+						return errors.New("Part " + part + " is still used in visual " + visual.Name + " of show " + show.Name)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GroupChange is a group that was changed or deleted because one of its parts was renamed or deleted
+type GroupChange struct {
+	Show    *Show
+	Visual  *Visual
+	Group   *Group
+	Deleted bool
+}
+
+// RenamePart replaces the part `oldName` by `newName` in all groups and returns the changed groups
+func (showCollection *ShowCollection) RenamePart(oldName string, newName string) []GroupChange {
+	return showCollection.changePart(oldName, func(parts []string) []string {
+		result := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part == oldName {
+				part = newName
+			}
+			result = append(result, part)
+		}
+		return result
+	})
+}
+
+// RemovePart removes the part from all groups and returns the changed groups. Groups without any parts left are
+// deleted.
+func (showCollection *ShowCollection) RemovePart(name string) []GroupChange {
+	return showCollection.changePart(name, func(parts []string) []string {
+		result := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part != name {
+				result = append(result, part)
+			}
+		}
+		return result
+	})
+}
+
+// changePart replaces the parts of all groups that use `name` by the result of `change`
+func (showCollection *ShowCollection) changePart(name string, change func(parts []string) []string) []GroupChange {
+	showCollection.mux.Lock()
+	defer showCollection.mux.Unlock()
+
+	changes := make([]GroupChange, 0)
+	for _, show := range showCollection.shows {
+		for _, visual := range show.Visuals() {
+			// copy, since groups might be deleted while iterating
+			groups := append([]*Group(nil), visual.Groups()...)
+			for _, group := range groups {
+				if !containsPart(group.Parts(), name) {
+					continue
+				}
+
+				parts := change(group.Parts())
+				if len(parts) == 0 {
+					visual.DeleteGroup(group)
+					changes = append(changes, GroupChange{Show: show, Visual: visual, Group: group, Deleted: true})
+					continue
+				}
+
+				group.SetParts(parts)
+				changes = append(changes, GroupChange{Show: show, Visual: visual, Group: group})
+			}
+		}
+	}
+
+	return changes
+}
+
+// containsPart checks if `name` is in the list of parts
+func containsPart(parts []string, name string) bool {
+	for _, part := range parts {
+		if part == name {
+			return true
+		}
+	}
+	return false
+}
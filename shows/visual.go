@@ -92,10 +92,18 @@ func (visual *Visual) DeleteGroup(group *Group) {
 }
 
 // Update decides about the changes that are caused by the visual for a certain timestep.
-func (visual *Visual) Update(hw *hardware.Hardware, nanoseconds int64) {
+// Effects that panic are disabled and returned, the other groups are updated normally.
+func (visual *Visual) Update(hw *hardware.Hardware, nanoseconds int64) []*EffectFailure {
+	var failures []*EffectFailure
+
 	for _, group := range visual.groups {
-		group.Update(hw, nanoseconds)
+		if failure := group.Update(hw, nanoseconds); failure != nil {
+			failure.VisualID = visual.ID
+			failures = append(failures, failure)
+		}
 	}
+
+	return failures
 }
 
 // FindParameter returns the parameter with the given ID and the belonging group or nil for malformed and non-existing IDs
@@ -0,0 +1,151 @@
+package timing
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// windowSize is the number of frames that are used for the statistics
+const windowSize = 100
+
+// FrameTimer collects the durations of the frames of the update loop
+type FrameTimer struct {
+	interval time.Duration
+
+	// ring buffers with the last frames
+	intervals []time.Duration
+	render    []time.Duration
+	output    []time.Duration
+	pos       int
+	count     int
+
+	dropped uint64
+
+	mux sync.Mutex
+}
+
+// Durations are the average and maximum duration of a step of the frames in milliseconds
+type Durations struct {
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+// Stats are the statistics of the last frames
+type Stats struct {
+	TargetFPS     float64   `json:"targetFps"`
+	FPS           float64   `json:"fps"`
+	Jitter        float64   `json:"jitter"`
+	RenderTime    Durations `json:"renderTime"`
+	OutputTime    Durations `json:"outputTime"`
+	DroppedFrames uint64    `json:"droppedFrames"`
+}
+
+// NewFrameTimer creates a new frame timer for the given frame rate
+func NewFrameTimer(fps float64) *FrameTimer {
+	timer := FrameTimer{}
+	timer.interval = time.Duration(float64(time.Second) / fps)
+	timer.intervals = make([]time.Duration, windowSize)
+	timer.render = make([]time.Duration, windowSize)
+	timer.output = make([]time.Duration, windowSize)
+
+	return &timer
+}
+
+// Interval returns the target time between two frames
+func (timer *FrameTimer) Interval() time.Duration {
+	return timer.interval
+}
+
+// AddFrame records the time since the previous frame and the time needed to render and output the frame
+func (timer *FrameTimer) AddFrame(interval time.Duration, render time.Duration, output time.Duration) {
+	timer.mux.Lock()
+	defer timer.mux.Unlock()
+
+	timer.intervals[timer.pos] = interval
+	timer.render[timer.pos] = render
+	timer.output[timer.pos] = output
+
+	timer.pos = (timer.pos + 1) % windowSize
+	if timer.count < windowSize {
+		timer.count++
+	}
+}
+
+// AddDropped records frames that were skipped because the previous frame took too long
+func (timer *FrameTimer) AddDropped(frames int) {
+	timer.mux.Lock()
+	defer timer.mux.Unlock()
+
+	timer.dropped += uint64(frames)
+}
+
+// DroppedFrames returns the number of frames that were skipped if `delta` passed between two ticks of a ticker with the
+// target interval. Ticks that are a bit late because of jitter are not counted.
+func DroppedFrames(delta time.Duration, interval time.Duration) int {
+	dropped := int((delta+interval/2)/interval) - 1
+	if dropped < 0 {
+		return 0
+	}
+	return dropped
+}
+
+// Stats returns the statistics of the last frames. The jitter is the standard deviation of the time between two
+// frames in milliseconds.
+func (timer *FrameTimer) Stats() Stats {
+	timer.mux.Lock()
+	defer timer.mux.Unlock()
+
+	stats := Stats{
+		TargetFPS:     float64(time.Second) / float64(timer.interval),
+		DroppedFrames: timer.dropped,
+	}
+
+	if timer.count == 0 {
+		return stats
+	}
+
+	// frame rate and jitter
+	var sum time.Duration
+	for i := 0; i < timer.count; i++ {
+		sum += timer.intervals[i]
+	}
+
+	mean := float64(sum) / float64(timer.count)
+	if mean > 0 {
+		stats.FPS = float64(time.Second) / mean
+	}
+
+	variance := 0.0
+	for i := 0; i < timer.count; i++ {
+		variance += math.Pow(float64(timer.intervals[i])-mean, 2)
+	}
+	stats.Jitter = milliseconds(time.Duration(math.Sqrt(variance / float64(timer.count))))
+
+	// durations of the steps
+	stats.RenderTime = timer.durations(timer.render)
+	stats.OutputTime = timer.durations(timer.output)
+
+	return stats
+}
+
+// durations calculates average and maximum of the recorded durations
+func (timer *FrameTimer) durations(values []time.Duration) Durations {
+	var sum, max time.Duration
+	for i := 0; i < timer.count; i++ {
+		sum += values[i]
+		if values[i] > max {
+			max = values[i]
+		}
+	}
+
+	return Durations{
+		Avg: milliseconds(sum / time.Duration(timer.count)),
+		Max: milliseconds(max),
+	}
+}
+
+// milliseconds converts a duration to milliseconds
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
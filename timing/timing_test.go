@@ -0,0 +1,143 @@
+package timing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDroppedFrames(t *testing.T) {
+	interval := 40 * time.Millisecond
+
+	tests := []struct {
+		delta    time.Duration
+		expected int
+	}{
+		{0, 0},
+		{10 * time.Millisecond, 0},
+		{40 * time.Millisecond, 0},
+		// late ticks are not counted until half of the next interval passed
+		{59 * time.Millisecond, 0},
+		{61 * time.Millisecond, 1},
+		{80 * time.Millisecond, 1},
+		{100 * time.Millisecond, 2},
+		{time.Second, 24},
+	}
+
+	for _, test := range tests {
+		if dropped := DroppedFrames(test.delta, interval); dropped != test.expected {
+			t.Errorf("DroppedFrames(%s, %s) = %d, expected %d", test.delta, interval, dropped, test.expected)
+		}
+	}
+}
+
+func TestFrameTimerStats(t *testing.T) {
+	type frame struct {
+		interval time.Duration
+		render   time.Duration
+		output   time.Duration
+	}
+
+	tests := []struct {
+		name     string
+		frames   []frame
+		dropped  []int
+		expected Stats
+	}{
+		{
+			name:     "no frames",
+			expected: Stats{TargetFPS: 25},
+		},
+		{
+			name: "constant frames",
+			frames: []frame{
+				{40 * time.Millisecond, 10 * time.Millisecond, 5 * time.Millisecond},
+				{40 * time.Millisecond, 10 * time.Millisecond, 5 * time.Millisecond},
+			},
+			expected: Stats{
+				TargetFPS:  25,
+				FPS:        25,
+				RenderTime: Durations{Avg: 10, Max: 10},
+				OutputTime: Durations{Avg: 5, Max: 5},
+			},
+		},
+		{
+			name: "varying frames",
+			frames: []frame{
+				{30 * time.Millisecond, 10 * time.Millisecond, 2 * time.Millisecond},
+				{50 * time.Millisecond, 30 * time.Millisecond, 4 * time.Millisecond},
+			},
+			expected: Stats{
+				TargetFPS:  25,
+				FPS:        25,
+				Jitter:     10,
+				RenderTime: Durations{Avg: 20, Max: 30},
+				OutputTime: Durations{Avg: 3, Max: 4},
+			},
+		},
+		{
+			name: "dropped frames",
+			frames: []frame{
+				{120 * time.Millisecond, 100 * time.Millisecond, 0},
+			},
+			dropped: []int{2, 1},
+			expected: Stats{
+				TargetFPS:     25,
+				FPS:           25.0 / 3,
+				RenderTime:    Durations{Avg: 100, Max: 100},
+				DroppedFrames: 3,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		timer := NewFrameTimer(25)
+		for _, f := range test.frames {
+			timer.AddFrame(f.interval, f.render, f.output)
+		}
+		for _, dropped := range test.dropped {
+			timer.AddDropped(dropped)
+		}
+
+		stats := timer.Stats()
+		if !statsEqual(stats, test.expected) {
+			t.Errorf("%s: Stats() = %+v, expected %+v", test.name, stats, test.expected)
+		}
+	}
+}
+
+func TestFrameTimerWindow(t *testing.T) {
+	timer := NewFrameTimer(25)
+
+	// the slow frames are replaced by the following ones, only the dropped frames are kept
+	for i := 0; i < windowSize; i++ {
+		timer.AddFrame(80*time.Millisecond, 50*time.Millisecond, 20*time.Millisecond)
+	}
+	timer.AddDropped(5)
+	for i := 0; i < windowSize; i++ {
+		timer.AddFrame(40*time.Millisecond, 10*time.Millisecond, 5*time.Millisecond)
+	}
+
+	expected := Stats{
+		TargetFPS:     25,
+		FPS:           25,
+		RenderTime:    Durations{Avg: 10, Max: 10},
+		OutputTime:    Durations{Avg: 5, Max: 5},
+		DroppedFrames: 5,
+	}
+	if stats := timer.Stats(); !statsEqual(stats, expected) {
+		t.Errorf("Stats() = %+v, expected %+v", stats, expected)
+	}
+}
+
+// statsEqual compares the statistics with a small tolerance for rounding errors
+func statsEqual(a Stats, b Stats) bool {
+	near := func(x float64, y float64) bool {
+		return math.Abs(x-y) < 1e-6
+	}
+
+	return near(a.TargetFPS, b.TargetFPS) && near(a.FPS, b.FPS) && near(a.Jitter, b.Jitter) &&
+		near(a.RenderTime.Avg, b.RenderTime.Avg) && near(a.RenderTime.Max, b.RenderTime.Max) &&
+		near(a.OutputTime.Avg, b.OutputTime.Avg) && near(a.OutputTime.Max, b.OutputTime.Max) &&
+		a.DroppedFrames == b.DroppedFrames
+}